@@ -0,0 +1,395 @@
+package sbtest
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type (
+	// The options parsed from an sbtest struct tag, refer to [Arbitrary].
+	arbitraryTag struct {
+		min   string
+		max   string
+		regex *syntax.Regexp
+	}
+)
+
+const (
+	// The name of the struct tag that [Arbitrary] reads field options from.
+	arbitraryTagName = "sbtest"
+	// The maximum length of generated strings, slices, and maps when no max
+	// is given, and the maximum number of repeats of unbounded regex
+	// operators.
+	maxArbitraryLen = 8
+	// The depth of nested pointers, slices, and maps past which no further
+	// values are generated, so recursive types terminate.
+	maxArbitraryDepth = 4
+	// The default range of generated floats.
+	maxArbitraryFloat = 1e6
+)
+
+// Generates a random value of type T using the supplied random source, so the
+// value is reproducible in the same way as the values from [Rand]. Booleans,
+// numbers, strings, slices, arrays, maps, pointers, and structs are filled in
+// recursively. Channels, functions, interfaces, and unexported struct fields
+// are left as their zero value. Integers are drawn from the full range of
+// their type, floats from [-1e6, 1e6], and strings, slices, and maps have a
+// length of at most 8. Pointers are sometimes nil and recursive types are cut
+// off after a few levels of nesting.
+//
+// The values of struct fields can be constrained with an sbtest struct tag
+// containing comma separated options:
+//   - min=N, max=N: The inclusive range of a number, or of the length of a
+//     string, slice, or map.
+//   - regex=RE: A string matching the whole regex. Any min and max are
+//     ignored. As the regex may contain commas it must be the last option.
+//   - "-": The field is left as its zero value.
+//
+// For a pointer the options apply to the value it points to.
+//
+//	type User struct {
+//		Name  string `sbtest:"regex=[A-Z][a-z]{2,9}"`
+//		Age   int    `sbtest:"min=0,max=130"`
+//		Roles []Role `sbtest:"max=3"`
+//	}
+//
+//	u := sbtest.Arbitrary[User](sbtest.NewRand(t))
+//
+// Arbitrary panics if a struct tag is malformed, as that is a mistake in the
+// test rather than a test failure.
+func Arbitrary[T any](r *Rand) T {
+	var rv T
+	arbitraryFill(r, reflect.ValueOf(&rv).Elem(), arbitraryTag{}, 0)
+	return rv
+}
+
+func arbitraryFill(r *Rand, v reflect.Value, tag arbitraryTag, depth int) {
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(r.IntN(2) == 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		bits := v.Type().Bits()
+		lo := arbitraryIntBound(tag.min, v, -1<<(bits-1))
+		hi := arbitraryIntBound(tag.max, v, 1<<(bits-1)-1)
+		checkArbitraryRange(lo > hi, lo, hi, v)
+		v.SetInt(arbitraryInt(r, lo, hi))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		lo := arbitraryUintBound(tag.min, v, 0)
+		hi := arbitraryUintBound(tag.max, v, math.MaxUint64>>(64-v.Type().Bits()))
+		checkArbitraryRange(lo > hi, lo, hi, v)
+		v.SetUint(arbitraryUint(r, lo, hi))
+	case reflect.Float32, reflect.Float64:
+		lo := arbitraryFloatBound(tag.min, v, -maxArbitraryFloat)
+		hi := arbitraryFloatBound(tag.max, v, maxArbitraryFloat)
+		checkArbitraryRange(lo > hi, lo, hi, v)
+		v.SetFloat(lo + r.Float64()*(hi-lo))
+	case reflect.Complex64, reflect.Complex128:
+		v.SetComplex(complex(
+			-maxArbitraryFloat+r.Float64()*2*maxArbitraryFloat,
+			-maxArbitraryFloat+r.Float64()*2*maxArbitraryFloat,
+		))
+	case reflect.String:
+		if tag.regex != nil {
+			var sb strings.Builder
+			arbitraryRegex(r, &sb, tag.regex)
+			v.SetString(sb.String())
+			return
+		}
+		runes := make([]rune, tag.length(r, v, depth))
+		for i := range runes {
+			runes[i] = arbitraryRune(r)
+		}
+		v.SetString(string(runes))
+	case reflect.Slice:
+		n := tag.length(r, v, depth)
+		v.Set(reflect.MakeSlice(v.Type(), n, n))
+		for i := range n {
+			arbitraryFill(r, v.Index(i), arbitraryTag{}, depth+1)
+		}
+	case reflect.Array:
+		for i := range v.Len() {
+			arbitraryFill(r, v.Index(i), arbitraryTag{}, depth+1)
+		}
+	case reflect.Map:
+		n := tag.length(r, v, depth)
+		v.Set(reflect.MakeMapWithSize(v.Type(), n))
+		// Duplicate keys are retried a bounded number of times so types with
+		// few possible keys, such as bool, still terminate.
+		for i := 0; v.Len() < n && i < n*10; i++ {
+			key := reflect.New(v.Type().Key()).Elem()
+			arbitraryFill(r, key, arbitraryTag{}, depth+1)
+			val := reflect.New(v.Type().Elem()).Elem()
+			arbitraryFill(r, val, arbitraryTag{}, depth+1)
+			v.SetMapIndex(key, val)
+		}
+	case reflect.Pointer:
+		if depth >= maxArbitraryDepth || r.IntN(4) == 0 {
+			v.SetZero()
+			return
+		}
+		v.Set(reflect.New(v.Type().Elem()))
+		arbitraryFill(r, v.Elem(), tag, depth+1)
+	case reflect.Struct:
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			fieldTag, skip := parseArbitraryTag(field)
+			if skip {
+				continue
+			}
+			arbitraryFill(r, v.Field(i), fieldTag, depth)
+		}
+	}
+}
+
+func arbitraryInt(r *Rand, lo int64, hi int64) int64 {
+	if lo == math.MinInt64 && hi == math.MaxInt64 {
+		return int64(r.Uint64())
+	}
+	return lo + int64(r.Uint64N(uint64(hi)-uint64(lo)+1))
+}
+
+func arbitraryUint(r *Rand, lo uint64, hi uint64) uint64 {
+	if lo == 0 && hi == math.MaxUint64 {
+		return r.Uint64()
+	}
+	return lo + r.Uint64N(hi-lo+1)
+}
+
+// Returns a random printable rune that is usually ASCII.
+func arbitraryRune(r *Rand) rune {
+	if r.IntN(8) != 0 {
+		return rune(' ' + r.IntN('~'-' '+1))
+	}
+	for {
+		if c := rune(0xa0 + r.IntN(0x2000-0xa0)); unicode.IsPrint(c) {
+			return c
+		}
+	}
+}
+
+// Returns the supplied character class ranges, which are pairs of inclusive
+// bounds, with the runes that [utf8.ValidRune] rejects removed. Negated
+// classes such as [^a] include the surrogate halves, which would otherwise be
+// written as the replacement character.
+func validRuneRanges(ranges []rune) []rune {
+	const surrogateMin, surrogateMax = 0xd800, 0xdfff
+	rv := make([]rune, 0, len(ranges)+2)
+	for i := 0; i < len(ranges); i += 2 {
+		lo, hi := max(ranges[i], 0), min(ranges[i+1], utf8.MaxRune)
+		if lo <= surrogateMax && hi >= surrogateMin {
+			if lo < surrogateMin {
+				rv = append(rv, lo, surrogateMin-1)
+			}
+			lo = surrogateMax + 1
+		}
+		if lo <= hi {
+			rv = append(rv, lo, hi)
+		}
+	}
+	return rv
+}
+
+// Writes a random string matching the supplied regex to the builder.
+func arbitraryRegex(r *Rand, sb *strings.Builder, re *syntax.Regexp) {
+	repeat := func(lo int, hi int) {
+		if hi < 0 {
+			hi = lo + maxArbitraryLen
+		}
+		for range lo + r.IntN(hi-lo+1) {
+			arbitraryRegex(r, sb, re.Sub[0])
+		}
+	}
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, iterRune := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 && r.IntN(2) == 0 {
+				iterRune = unicode.SimpleFold(iterRune)
+			}
+			sb.WriteRune(iterRune)
+		}
+	case syntax.OpCharClass:
+		ranges := validRuneRanges(re.Rune)
+		total := 0
+		for i := 0; i < len(ranges); i += 2 {
+			total += int(ranges[i+1]-ranges[i]) + 1
+		}
+		if total == 0 {
+			panic(fmt.Sprintf(
+				"sbtest: the character class %s has no valid runes", re,
+			))
+		}
+		n := r.IntN(total)
+		for i := 0; i < len(ranges); i += 2 {
+			size := int(ranges[i+1]-ranges[i]) + 1
+			if n < size {
+				sb.WriteRune(ranges[i] + rune(n))
+				break
+			}
+			n -= size
+		}
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		sb.WriteRune(arbitraryRune(r))
+	case syntax.OpCapture:
+		arbitraryRegex(r, sb, re.Sub[0])
+	case syntax.OpStar:
+		repeat(0, -1)
+	case syntax.OpPlus:
+		repeat(1, -1)
+	case syntax.OpQuest:
+		repeat(0, 1)
+	case syntax.OpRepeat:
+		repeat(re.Min, re.Max)
+	case syntax.OpConcat:
+		for _, iterSub := range re.Sub {
+			arbitraryRegex(r, sb, iterSub)
+		}
+	case syntax.OpAlternate:
+		arbitraryRegex(r, sb, re.Sub[r.IntN(len(re.Sub))])
+	}
+}
+
+// Parses the sbtest tag of the supplied field, returning true if the field
+// should be skipped.
+func parseArbitraryTag(field reflect.StructField) (arbitraryTag, bool) {
+	rv := arbitraryTag{}
+	raw, ok := field.Tag.Lookup(arbitraryTagName)
+	if !ok || raw == "" {
+		return rv, false
+	}
+	if raw == "-" {
+		return rv, true
+	}
+	for raw != "" {
+		var opt string
+		if strings.HasPrefix(raw, "regex=") {
+			opt, raw = raw, ""
+		} else {
+			opt, raw, _ = strings.Cut(raw, ",")
+		}
+		key, val, ok := strings.Cut(opt, "=")
+		if !ok {
+			panicArbitraryTag(field, fmt.Errorf("option %q has no value", opt))
+		}
+		switch key {
+		case "min":
+			rv.min = val
+		case "max":
+			rv.max = val
+		case "regex":
+			re, err := syntax.Parse(val, syntax.Perl)
+			if err != nil {
+				panicArbitraryTag(field, err)
+			}
+			if field.Type.Kind() != reflect.String &&
+				(field.Type.Kind() != reflect.Pointer ||
+					field.Type.Elem().Kind() != reflect.String) {
+				panicArbitraryTag(field, fmt.Errorf("regex requires a string field"))
+			}
+			rv.regex = re.Simplify()
+		default:
+			panicArbitraryTag(field, fmt.Errorf("unknown option %q", key))
+		}
+	}
+	return rv, false
+}
+
+func panicArbitraryTag(field reflect.StructField, err error) {
+	panic(fmt.Sprintf(
+		"sbtest: invalid %s tag on field %s: %v", arbitraryTagName, field.Name, err,
+	))
+}
+
+func arbitraryIntBound(raw string, v reflect.Value, def int64) int64 {
+	if raw == "" {
+		return def
+	}
+	rv, err := strconv.ParseInt(raw, 10, 64)
+	if err == nil && v.OverflowInt(rv) {
+		err = fmt.Errorf("%d overflows %s", rv, v.Type())
+	}
+	if err != nil {
+		panic(fmt.Sprintf("sbtest: invalid %s tag bound: %v", arbitraryTagName, err))
+	}
+	return rv
+}
+
+func arbitraryUintBound(raw string, v reflect.Value, def uint64) uint64 {
+	if raw == "" {
+		return def
+	}
+	rv, err := strconv.ParseUint(raw, 10, 64)
+	if err == nil && v.OverflowUint(rv) {
+		err = fmt.Errorf("%d overflows %s", rv, v.Type())
+	}
+	if err != nil {
+		panic(fmt.Sprintf("sbtest: invalid %s tag bound: %v", arbitraryTagName, err))
+	}
+	return rv
+}
+
+func arbitraryFloatBound(raw string, v reflect.Value, def float64) float64 {
+	if raw == "" {
+		return def
+	}
+	rv, err := strconv.ParseFloat(raw, 64)
+	if err == nil && v.OverflowFloat(rv) {
+		err = fmt.Errorf("%g overflows %s", rv, v.Type())
+	}
+	if err != nil {
+		panic(fmt.Sprintf("sbtest: invalid %s tag bound: %v", arbitraryTagName, err))
+	}
+	return rv
+}
+
+// Panics with a description of the bounds of the supplied value if invalid is
+// true, which is the case when the min option of a tag is greater than its
+// max option.
+func checkArbitraryRange(invalid bool, lo any, hi any, v reflect.Value) {
+	if invalid {
+		panic(fmt.Sprintf(
+			"sbtest: invalid %s tag bound: min %v is greater than max %v for %s",
+			arbitraryTagName, lo, hi, v.Type(),
+		))
+	}
+}
+
+// Returns a random length for a string, slice, or map within the bounds of the
+// tag. Past the maximum depth the minimum length is always used.
+func (a arbitraryTag) length(r *Rand, v reflect.Value, depth int) int {
+	lo := 0
+	if a.min != "" {
+		lo = int(arbitraryLenBound(a.min))
+	}
+	hi := lo + maxArbitraryLen
+	if a.max != "" {
+		hi = int(arbitraryLenBound(a.max))
+	} else if a.min == "" {
+		hi = maxArbitraryLen
+	}
+	checkArbitraryRange(lo > hi, lo, hi, v)
+	if depth >= maxArbitraryDepth {
+		return lo
+	}
+	return lo + r.IntN(hi-lo+1)
+}
+
+func arbitraryLenBound(raw string) int64 {
+	rv, err := strconv.ParseInt(raw, 10, 32)
+	if err == nil && rv < 0 {
+		err = fmt.Errorf("length %d is negative", rv)
+	}
+	if err != nil {
+		panic(fmt.Sprintf("sbtest: invalid %s tag bound: %v", arbitraryTagName, err))
+	}
+	return rv
+}
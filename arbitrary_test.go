@@ -0,0 +1,66 @@
+package sbtest
+
+import (
+	"regexp"
+	"testing"
+	"unicode/utf8"
+)
+
+type arbitraryTagged struct {
+	Age   int    `sbtest:"min=18,max=65"`
+	Name  string `sbtest:"regex=[A-Z][a-z]{2,9}"`
+	Other string `sbtest:"regex=[^a]{5}"`
+	Tags  []int  `sbtest:"min=1,max=3"`
+	Skip  *int   `sbtest:"-"`
+}
+
+func TestArbitraryTags(t *testing.T) {
+	r := NewRandWithSeed(1)
+	name := regexp.MustCompile(`^[A-Z][a-z]{2,9}$`)
+	for range 1000 {
+		v := Arbitrary[arbitraryTagged](r)
+		True(t, v.Age >= 18 && v.Age <= 65)
+		True(t, name.MatchString(v.Name))
+		True(t, utf8.ValidString(v.Other))
+		Eq(t, 5, utf8.RuneCountInString(v.Other))
+		True(t, len(v.Tags) >= 1 && len(v.Tags) <= 3)
+		Nil(t, v.Skip)
+	}
+}
+
+func TestArbitraryDeterministic(t *testing.T) {
+	type value struct {
+		A []string
+		B map[int]float64
+		C *struct{ D uint8 }
+	}
+	a := Arbitrary[value](NewRandWithSeed(7))
+	b := Arbitrary[value](NewRandWithSeed(7))
+	MatchNested(t, a, b)
+}
+
+func TestValidRuneRanges(t *testing.T) {
+	SlicesMatch(
+		t,
+		[]rune{0, 0xd7ff, 0xe000, utf8.MaxRune},
+		validRuneRanges([]rune{0, utf8.MaxRune}),
+	)
+	SlicesMatch(t, []rune{'a', 'z'}, validRuneRanges([]rune{'a', 'z'}))
+	SlicesMatch(t, []rune{}, validRuneRanges([]rune{0xd800, 0xdfff}))
+	SlicesMatch(
+		t, []rune{0xe000, 0xe010}, validRuneRanges([]rune{0xd900, 0xe010}),
+	)
+}
+
+func TestArbitraryRegexValidRunes(t *testing.T) {
+	r := NewRandWithSeed(3)
+	for range 1000 {
+		v := Arbitrary[struct {
+			S string `sbtest:"regex=[^a-z]{20}"`
+		}](r)
+		True(t, utf8.ValidString(v.S))
+		for _, iterRune := range v.S {
+			True(t, iterRune != utf8.RuneError)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package sbtest
+
+import (
+	"math/big"
+	"testing"
+)
+
+type (
+	// The set of arbitrary precision number types that can be used with
+	// [EqBig].
+	bigNum[T any] interface {
+		*big.Int | *big.Float | *big.Rat
+		Cmp(y T) int
+	}
+)
+
+// Tests that the supplied arbitrary precision numbers are equal according to
+// their Cmp method. [Eq] cannot be used with these types because it compares
+// the pointers rather than the values. Two nil numbers are equal. On failure
+// the numbers are printed in their decimal string form. For [big.Float] the
+// shortest decimal representation that uniquely identifies the value is used,
+// and for [big.Rat] the fraction is printed in lowest terms.
+func EqBig[T bigNum[T]](t testing.TB, expected T, got T) {
+	t.Helper()
+	eNil, gNil := expected == nil, got == nil
+	if (eNil && gNil) || (!eNil && !gNil && expected.Cmp(got) == 0) {
+		return
+	}
+	f, line := callerLoc()
+	FormatError(
+		t, fmtBig(expected), fmtBig(got),
+		"The supplied numbers were not equal but were expected to be.",
+		f, line,
+	)
+}
+
+func fmtBig[T bigNum[T]](v T) string {
+	switch v := any(v).(type) {
+	case *big.Int:
+		if v != nil {
+			return v.String()
+		}
+	case *big.Float:
+		if v != nil {
+			return v.Text('g', -1)
+		}
+	case *big.Rat:
+		if v != nil {
+			return v.RatString()
+		}
+	}
+	return "<nil>"
+}
@@ -0,0 +1,170 @@
+package sbtest
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type (
+	// An in process message bus that can be used in place of a real broker
+	// when testing event driven code. Messages are published to named topics
+	// and delivered to every subscriber of that topic. Every published message
+	// is recorded so that assertions can be made with [Delivered] and
+	// [NothingDelivered]. All methods are safe for concurrent use.
+	Bus[T any] struct {
+		mu        sync.Mutex
+		changed   chan struct{}
+		nextID    int
+		subs      map[string][]busSub[T]
+		published map[string][]T
+	}
+
+	busSub[T any] struct {
+		id int
+		fn func(msg T)
+	}
+)
+
+// Creates a new message bus with no subscribers.
+func NewBus[T any]() *Bus[T] {
+	return &Bus[T]{
+		changed:   make(chan struct{}),
+		subs:      map[string][]busSub[T]{},
+		published: map[string][]T{},
+	}
+}
+
+// Publishes the supplied message to the supplied topic. The message is
+// delivered synchronously to every subscriber of the topic, in the order they
+// subscribed, before Publish returns. The message is recorded even if the topic
+// has no subscribers.
+func (b *Bus[T]) Publish(topic string, msg T) {
+	b.mu.Lock()
+	b.published[topic] = append(b.published[topic], msg)
+	subs := slices.Clone(b.subs[topic])
+	close(b.changed)
+	b.changed = make(chan struct{})
+	b.mu.Unlock()
+
+	for _, iterSub := range subs {
+		iterSub.fn(msg)
+	}
+}
+
+// Registers the supplied function to be called with every message that is
+// published to the supplied topic from now on. The returned function removes
+// the subscription.
+func (b *Bus[T]) Subscribe(topic string, fn func(msg T)) (unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	id := b.nextID
+	b.nextID++
+	b.subs[topic] = append(b.subs[topic], busSub[T]{id: id, fn: fn})
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.subs[topic] = slices.DeleteFunc(
+			b.subs[topic], func(s busSub[T]) bool { return s.id == id },
+		)
+	}
+}
+
+// Returns a copy of all messages that have been published to the supplied
+// topic so far, in the order they were published.
+func (b *Bus[T]) Published(topic string) []T {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return slices.Clone(b.published[topic])
+}
+
+// Tests that a message equal to msg is published to the supplied topic before
+// the timeout expires. Messages that were published before the assertion was
+// made are also considered. For equality rules refer to the language
+// reference: https://go.dev/ref/spec#Comparison_operators
+//
+// All messages published to the topic are listed on failure.
+func Delivered[T comparable](
+	t testing.TB,
+	bus *Bus[T],
+	topic string,
+	msg T,
+	timeout time.Duration,
+) {
+	t.Helper()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		bus.mu.Lock()
+		found, changed := slices.Contains(bus.published[topic], msg), bus.changed
+		bus.mu.Unlock()
+		if found {
+			return
+		}
+		select {
+		case <-changed:
+		case <-timer.C:
+			f, line := callerLoc()
+			FormatError(
+				t, msg, nil,
+				fmt.Sprintf(
+					"The message was not delivered within the timeout | Topic: %s | Timeout: %s\nPublished:%s",
+					topic, timeout, fmtPublished(bus.Published(topic)),
+				),
+				f, line,
+			)
+			return
+		}
+	}
+}
+
+// Tests that no message is published to the supplied topic for the duration
+// of the supplied wait, including messages that were published before the
+// assertion was made. The assertion always blocks for the full wait unless a
+// message is published.
+func NothingDelivered[T any](
+	t testing.TB,
+	bus *Bus[T],
+	topic string,
+	wait time.Duration,
+) {
+	t.Helper()
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	for {
+		bus.mu.Lock()
+		n, changed := len(bus.published[topic]), bus.changed
+		bus.mu.Unlock()
+		if n > 0 {
+			f, line := callerLoc()
+			FormatError(
+				t, 0, n,
+				fmt.Sprintf(
+					"Messages were delivered but none were expected | Topic: %s\nPublished:%s",
+					topic, fmtPublished(bus.Published(topic)),
+				),
+				f, line,
+			)
+			return
+		}
+		select {
+		case <-changed:
+		case <-timer.C:
+			return
+		}
+	}
+}
+
+func fmtPublished[T any](msgs []T) string {
+	if len(msgs) == 0 {
+		return " <no messages>"
+	}
+	var sb strings.Builder
+	for i, iterMsg := range msgs {
+		fmt.Fprintf(&sb, "\n  %d: %v", i, iterMsg)
+	}
+	return sb.String()
+}
@@ -0,0 +1,112 @@
+package sbtest
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const (
+	// The number of bytes shown on each line of a hex dump.
+	hexDumpWidth = 16
+	// The number of lines shown before and after the line containing the
+	// first difference in a hex dump.
+	hexDumpContext = 2
+)
+
+// Tests that the supplied byte slices are equal. On failure a side by side hex
+// dump of both slices is shown, limited to a window of lines around the first
+// offset at which they differ. Lines that contain a difference are marked with
+// a '*'.
+func EqBytes(t testing.TB, expected []byte, got []byte) {
+	t.Helper()
+	f, line := callerLoc()
+	bytesEq(t, expected, got, "The byte slices were not equal", f, line)
+}
+
+func bytesEq(
+	t testing.TB,
+	expected []byte,
+	got []byte,
+	msg string,
+	f string,
+	line int,
+) bool {
+	t.Helper()
+	if bytes.Equal(expected, got) {
+		return true
+	}
+	off := 0
+	for off < len(expected) && off < len(got) && expected[off] == got[off] {
+		off++
+	}
+	FormatError(
+		t, fmtByteAt(expected, off), fmtByteAt(got, off),
+		fmt.Sprintf(
+			"%s | Offset: %d | Expected Len: %d | Got Len: %d\nHex Dump:%s",
+			msg, off, len(expected), len(got), hexDumpDiff(expected, got, off),
+		),
+		f, line,
+	)
+	return false
+}
+
+func fmtByteAt(b []byte, off int) string {
+	if off >= len(b) {
+		return "<end of slice>"
+	}
+	return fmt.Sprintf("0x%02x", b[off])
+}
+
+// Returns a side by side hex dump of the supplied slices limited to the lines
+// around the supplied offset.
+func hexDumpDiff(expected []byte, got []byte, off int) string {
+	firstLine := max(off/hexDumpWidth-hexDumpContext, 0)
+	lastLine := off/hexDumpWidth + hexDumpContext
+	maxLen := max(len(expected), len(got))
+	lastLine = min(lastLine, (maxLen-1)/hexDumpWidth)
+
+	var sb strings.Builder
+	colWidth := hexDumpWidth*3 - 1
+	fmt.Fprintf(
+		&sb, "\n  %-8s  %-*s   %s", "Offset", colWidth, "Expected", "Got",
+	)
+	if firstLine > 0 {
+		sb.WriteString("\n  ...")
+	}
+	for i := firstLine; i <= lastLine; i++ {
+		start := i * hexDumpWidth
+		differs := false
+		for j := start; j < start+hexDumpWidth && j < maxLen; j++ {
+			if j >= len(expected) || j >= len(got) || expected[j] != got[j] {
+				differs = true
+				break
+			}
+		}
+		marker := ""
+		if differs {
+			marker = " *"
+		}
+		fmt.Fprintf(
+			&sb, "\n  %08x  %s   %s%s",
+			start, hexDumpLine(expected, start), hexDumpLine(got, start), marker,
+		)
+	}
+	if (lastLine+1)*hexDumpWidth < maxLen {
+		sb.WriteString("\n  ...")
+	}
+	return sb.String()
+}
+
+func hexDumpLine(b []byte, start int) string {
+	cells := make([]string, hexDumpWidth)
+	for i := range cells {
+		if start+i < len(b) {
+			cells[i] = fmt.Sprintf("%02x", b[start+i])
+		} else {
+			cells[i] = "  "
+		}
+	}
+	return strings.Join(cells, " ")
+}
@@ -0,0 +1,103 @@
+package sbtest
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+)
+
+const pkgPath = "github.com/barbell-math/smoothbrain-test"
+
+// The set of function names that have been marked as helpers with
+// [MarkHelper].
+var helpers sync.Map
+
+// Marks the calling function as an assertion helper. When an assertion fails
+// the file and line that is reported is the first frame on the call stack that
+// is not inside this module and is not a marked helper. This allows
+// domain-specific assertions to be built on top of the assertions in this
+// package while still reporting the location in the test that called them.
+//
+// Every assertion in this package also calls [testing.TB.Helper], so the
+// file:line prefix that go test adds to the failure skips the same frames.
+// The testing package only allows a function to mark itself as a helper, so
+// for both locations to agree a wrapper needs to call both t.Helper and
+// MarkHelper, as shown below.
+//
+//	func EqUser(t testing.TB, expected User, got User) {
+//		t.Helper()
+//		sbtest.MarkHelper()
+//		sbtest.Eq(t, expected.Name, got.Name)
+//	}
+//
+// Like [testing.TB.Helper] this may be called concurrently and marking a
+// function more than once has no additional effect.
+func MarkHelper() {
+	pcs := make([]uintptr, 1)
+	if runtime.Callers(2, pcs) == 0 {
+		return
+	}
+	frame, _ := runtime.CallersFrames(pcs).Next()
+	helpers.Store(frame.Function, struct{}{})
+}
+
+// Returns the file and line of the first frame on the call stack that is not
+// inside this module and is not a function marked with [MarkHelper]. This is
+// the location that assertions report on failure, and is intended to be
+// passed to [FormatError] by assertions written outside of this module. Such
+// assertions must call MarkHelper so that their own frame is skipped.
+//
+//	func UserValid(t testing.TB, u User) {
+//		t.Helper()
+//		sbtest.MarkHelper()
+//		if !u.Valid() {
+//			f, line := sbtest.CallerLoc()
+//			sbtest.FormatError(t, true, false, "The user was not valid.", f, line)
+//		}
+//	}
+func CallerLoc() (string, int) {
+	return callerLoc()
+}
+
+func callerLoc() (string, int) {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !isPkgFrame(frame.Function) && !isHelper(frame.Function) {
+			return frame.File, frame.Line
+		}
+		if !more {
+			return frame.File, frame.Line
+		}
+	}
+}
+
+func isPkgFrame(function string) bool {
+	_, ok := pkgFuncName(function)
+	return ok
+}
+
+// Returns the name of the supplied function with its package path removed if
+// it is in this package or one of its subpackages, such as sbgrpc.
+func pkgFuncName(function string) (string, bool) {
+	rest, ok := strings.CutPrefix(function, pkgPath)
+	if !ok {
+		return "", false
+	}
+	if sub, ok := strings.CutPrefix(rest, "/"); ok {
+		i := strings.Index(sub, ".")
+		if i < 0 {
+			return "", false
+		}
+		rest = sub[i:]
+	}
+	name, ok := strings.CutPrefix(rest, ".")
+	return name, ok && !strings.Contains(name, "/")
+}
+
+func isHelper(function string) bool {
+	_, ok := helpers.Load(function)
+	return ok
+}
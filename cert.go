@@ -0,0 +1,105 @@
+package sbtest
+
+import (
+	"crypto/x509"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// Tests that the supplied certificate is valid for the supplied hostname
+// according to [x509.Certificate.VerifyHostname]. The hostname may also be an
+// IP address. The subject and the names the certificate is valid for are shown
+// on failure. The validity period and the issuer of the certificate are not
+// checked.
+func CertValidFor(t testing.TB, cert *x509.Certificate, hostname string) {
+	t.Helper()
+	f, line := callerLoc()
+	if !certNotNil(t, cert, f, line) {
+		return
+	}
+	if err := cert.VerifyHostname(hostname); err != nil {
+		FormatError(
+			t, hostname, fmtCertNames(cert),
+			fmt.Sprintf(
+				"The certificate was not valid for the hostname | Subject: %s%s",
+				cert.Subject, errChain(err),
+			),
+			f, line,
+		)
+	}
+}
+
+// Tests that the supplied certificate does not expire before the supplied
+// time, meaning its NotAfter time must be after tm. This is useful for testing
+// that rotation code issues certificates with a long enough lifetime.
+func CertExpiresAfter(t testing.TB, cert *x509.Certificate, tm time.Time) {
+	t.Helper()
+	f, line := callerLoc()
+	if !certNotNil(t, cert, f, line) {
+		return
+	}
+	if !cert.NotAfter.After(tm) {
+		FormatError(
+			t, fmt.Sprintf("after %s", tm.UTC()), cert.NotAfter.UTC(),
+			fmt.Sprintf(
+				"The certificate expires too early | Subject: %s | Serial: %s",
+				cert.Subject, cert.SerialNumber,
+			),
+			f, line,
+		)
+	}
+}
+
+// Tests that the supplied certificate was issued by the supplied issuer. The
+// issuer field of the certificate must match the subject of the issuer and the
+// signature of the certificate must have been created by the issuer's key. The
+// subject and issuer of both certificates are shown on failure.
+func CertIssuedBy(t testing.TB, cert *x509.Certificate, issuer *x509.Certificate) {
+	t.Helper()
+	f, line := callerLoc()
+	if !certNotNil(t, cert, f, line) || !certNotNil(t, issuer, f, line) {
+		return
+	}
+	if cert.Issuer.String() != issuer.Subject.String() {
+		FormatError(
+			t, issuer.Subject.String(), cert.Issuer.String(),
+			fmt.Sprintf(
+				"The certificate issuer did not match the issuer's subject | Subject: %s",
+				cert.Subject,
+			),
+			f, line,
+		)
+		return
+	}
+	if err := cert.CheckSignatureFrom(issuer); err != nil {
+		FormatError(
+			t, nil, errChain(err),
+			fmt.Sprintf(
+				"The certificate was not signed by the issuer | Subject: %s | Issuer: %s",
+				cert.Subject, issuer.Subject,
+			),
+			f, line,
+		)
+	}
+}
+
+func certNotNil(t testing.TB, cert *x509.Certificate, f string, line int) bool {
+	t.Helper()
+	if cert == nil {
+		FormatError(
+			t, "a certificate", nil,
+			"The supplied certificate was nil.",
+			f, line,
+		)
+		return false
+	}
+	return true
+}
+
+func fmtCertNames(cert *x509.Certificate) string {
+	return fmt.Sprintf(
+		"DNS Names: %v | IP Addresses: %v | Common Name: %s",
+		cert.DNSNames, cert.IPAddresses, cert.Subject.CommonName,
+	)
+}
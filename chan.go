@@ -0,0 +1,173 @@
+package sbtest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// Tests that a value is received from the supplied channel before the timeout
+// expires and that the received value is equal to the expected value. For
+// equality rules refer to the language reference:
+// https://go.dev/ref/spec#Comparison_operators
+func ChanReceives[T comparable](
+	t testing.TB,
+	ch <-chan T,
+	expected T,
+	timeout time.Duration,
+) {
+	t.Helper()
+	f, line := callerLoc()
+	got, ok := chanReceive(t, ch, timeout, f, line)
+	if ok && expected != got {
+		FormatError(
+			t, expected, got,
+			"The value received from the channel was not equal to the expected value.",
+			f, line,
+		)
+	}
+}
+
+// Tests that a value is received from the supplied channel before the timeout
+// expires. The received value is returned so further assertions can be made on
+// it.
+func ChanReceivesWithin[T any](
+	t testing.TB,
+	ch <-chan T,
+	timeout time.Duration,
+) T {
+	t.Helper()
+	f, line := callerLoc()
+	rv, _ := chanReceive(t, ch, timeout, f, line)
+	return rv
+}
+
+func chanReceive[T any](
+	t testing.TB,
+	ch <-chan T,
+	timeout time.Duration,
+	file string,
+	line int,
+) (T, bool) {
+	t.Helper()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case v, ok := <-ch:
+		if !ok {
+			FormatError(
+				t, "value", "closed channel",
+				"The channel was closed before a value was received.",
+				file, line,
+			)
+		}
+		return v, ok
+	case <-timer.C:
+		FormatError(
+			t, "value", "timeout",
+			fmt.Sprintf(
+				"No value was received from the channel before the timeout expired | Timeout: %s",
+				timeout,
+			),
+			file, line,
+		)
+	}
+	var zero T
+	return zero, false
+}
+
+// Tests that the supplied channel is closed before the timeout expires. Any
+// value that is received from the channel before it is closed results in a
+// failure.
+func ChanClosed[T any](t testing.TB, ch <-chan T, timeout time.Duration) {
+	t.Helper()
+	f, line := callerLoc()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case v, ok := <-ch:
+		if ok {
+			FormatError(
+				t, "closed channel", v,
+				"A value was received from the channel when it was expected to be closed.",
+				f, line,
+			)
+		}
+	case <-timer.C:
+		FormatError(
+			t, "closed channel", "timeout",
+			fmt.Sprintf(
+				"The channel was not closed before the timeout expired | Timeout: %s",
+				timeout,
+			),
+			f, line,
+		)
+	}
+}
+
+// Tests that no value is immediately available on the supplied channel. A
+// closed channel has no values available and will pass this test.
+func ChanEmpty[T any](t testing.TB, ch <-chan T) {
+	t.Helper()
+	select {
+	case v, ok := <-ch:
+		if ok {
+			f, line := callerLoc()
+			FormatError(
+				t, "empty channel", v,
+				"A value was received from the channel when it was expected to be empty.",
+				f, line,
+			)
+		}
+	default:
+	}
+}
+
+// Collects all values sent on the supplied channel until it is closed or the
+// timeout expires and tests that the collected values match the expected
+// values. Refer to [SlicesMatch] for the matching rules.
+func ChanDrainsTo[T comparable](
+	t testing.TB,
+	ch <-chan T,
+	expected []T,
+	timeout time.Duration,
+) {
+	t.Helper()
+	f, line := callerLoc()
+	slicesMatch(t, expected, chanDrain(ch, timeout), f, line)
+}
+
+// Collects all values sent on the supplied channel until it is closed or the
+// timeout expires and tests that the collected values match the expected
+// values, ignoring order. Refer to [SlicesMatchUnordered] for the matching
+// rules.
+func ChanDrainsToUnordered[T comparable](
+	t testing.TB,
+	ch <-chan T,
+	expected []T,
+	timeout time.Duration,
+) {
+	t.Helper()
+	f, line := callerLoc()
+	slicesMatchUnordered(t, expected, chanDrain(ch, timeout), f, line)
+}
+
+func chanDrain[T any](ch <-chan T, timeout time.Duration) []T {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	rv := []T{}
+	for {
+		select {
+		case v, ok := <-ch:
+			if !ok {
+				return rv
+			}
+			rv = append(rv, v)
+		case <-timer.C:
+			return rv
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package sbtest
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var (
+	// Matches ANSI CSI sequences, such as colors and cursor movement, and OSC
+	// sequences, such as hyperlinks and window titles.
+	ansiEscapeRe = regexp.MustCompile(
+		`\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)`,
+	)
+)
+
+// Runs the supplied main function as if it were a command line program that
+// was started with the supplied args and stdin. The exit code and everything
+// the program wrote to stdout and stderr are returned so that assertions can
+// be made on them. A main function that returns without calling [os.Exit] has
+// an exit code of zero, the same as a real program.
+//
+//	res := sbtest.RunCLI(t, main, []string{"-v", "input.txt"}, "")
+//	res.CodeEq(t, 0)
+//	res.StdoutGolden(t, "testdata/verbose.golden")
+//
+// The program is run in a subprocess in the same way as [ExitsWith], so calls
+// to [os.Exit] and [log.Fatal] end the subprocess instead of the test and the
+// restrictions listed there apply. In the subprocess [os.Args] is set to the
+// name of the test binary followed by args and [flag.CommandLine] is replaced
+// with an empty flag set, so that main can define and parse its flags
+// normally.
+//
+// Unlike [ExitsWith], RunCLI can be called several times from the same test,
+// though still not from the same line. The assertion methods of the returned
+// [ExitResult] do nothing in a subprocess that was started for a different
+// call, so they should be used instead of asserting on its fields directly.
+func RunCLI(t testing.TB, main func(), args []string, stdin string) ExitResult {
+	t.Helper()
+	f, line := callerLoc()
+	rv, _ := runInSubprocess(
+		t,
+		func() {
+			os.Args = append([]string{os.Args[0]}, args...)
+			flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+			main()
+		},
+		stdin, f, line,
+	)
+	rv.Stderr, _ = strings.CutSuffix(rv.Stderr, exitsWithReturnedMarker+"\n")
+	return rv
+}
+
+// Tests that the program exited with the expected exit code.
+func (r ExitResult) CodeEq(t testing.TB, expected int) {
+	t.Helper()
+	if r.inSubprocess {
+		return
+	}
+	if r.Code != expected {
+		f, line := callerLoc()
+		FormatError(
+			t, expected, r.Code,
+			fmt.Sprintf(
+				"The program did not exit with the expected code.\nStdout: %s\nStderr: %s",
+				r.Stdout, r.Stderr,
+			),
+			f, line,
+		)
+	}
+}
+
+// Tests that the stdout of the program is equal to the expected value.
+func (r ExitResult) StdoutEq(t testing.TB, expected string) {
+	t.Helper()
+	if r.inSubprocess {
+		return
+	}
+	f, line := callerLoc()
+	exitOutputEq(t, "stdout", expected, r.Stdout, f, line)
+}
+
+// Tests that the stderr of the program is equal to the expected value.
+func (r ExitResult) StderrEq(t testing.TB, expected string) {
+	t.Helper()
+	if r.inSubprocess {
+		return
+	}
+	f, line := callerLoc()
+	exitOutputEq(t, "stderr", expected, r.Stderr, f, line)
+}
+
+// Tests that the stdout of the program is equal to the contents of the golden
+// file at the supplied path. Refer to [EqGolden] for how golden files are
+// updated.
+func (r ExitResult) StdoutGolden(t testing.TB, path string) {
+	t.Helper()
+	if r.inSubprocess {
+		return
+	}
+	f, line := callerLoc()
+	eqGolden(t, path, r.Stdout, f, line)
+}
+
+// Tests that the stderr of the program is equal to the contents of the golden
+// file at the supplied path. Refer to [EqGolden] for how golden files are
+// updated.
+func (r ExitResult) StderrGolden(t testing.TB, path string) {
+	t.Helper()
+	if r.inSubprocess {
+		return
+	}
+	f, line := callerLoc()
+	eqGolden(t, path, r.Stderr, f, line)
+}
+
+func exitOutputEq(
+	t testing.TB,
+	name string,
+	expected string,
+	got string,
+	f string,
+	line int,
+) {
+	t.Helper()
+	if expected != got {
+		FormatError(
+			t, expected, got,
+			fmt.Sprintf(
+				"The programs %s did not match.\nDiff:%s",
+				name, lineDiff(expected, got),
+			),
+			f, line,
+		)
+	}
+}
+
+// Tests that the supplied output strings are equal after ANSI escape sequences
+// have been removed from both and their whitespace has been normalized.
+// Trailing whitespace is removed from every line and trailing empty lines are
+// removed, so output that is padded to the width of the terminal compares
+// equal to unpadded output. This allows tests of colorized output to pass
+// regardless of whether color support is enabled. The stripped strings are
+// compared and shown in the diff on failure.
+func OutputEqStripped(t testing.TB, expected string, got string) {
+	t.Helper()
+	eStripped, gStripped := stripOutput(expected), stripOutput(got)
+	if eStripped != gStripped {
+		f, line := callerLoc()
+		FormatError(
+			t, eStripped, gStripped,
+			"The stripped output did not match.\nDiff:"+
+				lineDiff(eStripped, gStripped),
+			f, line,
+		)
+	}
+}
+
+// Removes ANSI escape sequences, trailing whitespace on each line, and
+// trailing empty lines from the supplied output.
+func stripOutput(output string) string {
+	output = ansiEscapeRe.ReplaceAllString(output, "")
+	output = strings.ReplaceAll(output, "\r\n", "\n")
+	lines := strings.Split(output, "\n")
+	for i, iterLine := range lines {
+		lines[i] = strings.TrimRight(iterLine, " \t\r")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
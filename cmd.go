@@ -0,0 +1,305 @@
+package sbtest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+type (
+	// Wraps an [exec.Cmd] so that it runs with a timeout and provides
+	// assertions on its exit code and output. The embedded [exec.Cmd] can be
+	// used to configure the command, such as setting Dir, Env, or Stdin,
+	// before it is started. Stdout and Stderr are captured by Cmd and must not
+	// be set.
+	//
+	// If the command is still running when the test completes it is killed.
+	Cmd struct {
+		*exec.Cmd
+		t       testing.TB
+		ctx     context.Context
+		cancel  context.CancelFunc
+		timeout time.Duration
+		stdout  bytes.Buffer
+		stderr  bytes.Buffer
+		started bool
+		waited  bool
+		code    int
+	}
+)
+
+// Creates a new command that will run the named program with the supplied
+// arguments. The command is killed if it has not finished before the timeout
+// expires, starting from the time it is started. The command is not started
+// until Start or Run is called.
+func NewCmd(
+	t testing.TB,
+	timeout time.Duration,
+	name string,
+	args ...string,
+) *Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	rv := &Cmd{
+		Cmd:     exec.CommandContext(ctx, name, args...),
+		t:       t,
+		ctx:     ctx,
+		cancel:  cancel,
+		timeout: timeout,
+	}
+	rv.Cmd.Stdout = &rv.stdout
+	rv.Cmd.Stderr = &rv.stderr
+	t.Cleanup(func() {
+		cancel()
+		if rv.started && !rv.waited {
+			rv.Cmd.Wait()
+		}
+	})
+	return rv
+}
+
+// Creates a new command with [NewCmd], runs it, and waits for it to finish.
+func RunCmd(
+	t testing.TB,
+	timeout time.Duration,
+	name string,
+	args ...string,
+) *Cmd {
+	t.Helper()
+	return NewCmd(t, timeout, name, args...).Run()
+}
+
+// Starts the command and waits for it to finish. Returns the command so that
+// assertions can be chained from it.
+func (c *Cmd) Run() *Cmd {
+	c.t.Helper()
+	return c.Start().Wait()
+}
+
+// Starts the command without waiting for it to finish. Returns the command so
+// that assertions can be chained from it. The test fails if the command could
+// not be started.
+func (c *Cmd) Start() *Cmd {
+	c.t.Helper()
+	if c.started {
+		return c
+	}
+	c.started = true
+	if err := c.Cmd.Start(); err != nil {
+		f, line := callerLoc()
+		FormatError(
+			c.t, nil, errChain(err),
+			fmt.Sprintf("The command could not be started | Cmd: %s", c),
+			f, line,
+		)
+		c.waited = true
+		c.code = -1
+		return c
+	}
+	timer := time.AfterFunc(c.timeout, c.cancel)
+	context.AfterFunc(c.ctx, func() { timer.Stop() })
+	return c
+}
+
+// Waits for the command to finish, starting it first if it has not been
+// started. Returns the command so that assertions can be chained from it. The
+// test fails if the command was killed because it did not finish before the
+// timeout expired.
+func (c *Cmd) Wait() *Cmd {
+	c.t.Helper()
+	if !c.started {
+		c.Start()
+	}
+	if c.waited {
+		return c
+	}
+	c.waited = true
+
+	err := c.Cmd.Wait()
+	timedOut := c.ctx.Err() != nil
+	c.cancel()
+	c.code = c.Cmd.ProcessState.ExitCode()
+
+	var exitErr *exec.ExitError
+	if timedOut {
+		f, line := callerLoc()
+		FormatError(
+			c.t, fmt.Sprintf("finished within %s", c.timeout), "killed",
+			fmt.Sprintf(
+				"The command did not finish before the timeout expired | Cmd: %s%s",
+				c, c.output(),
+			),
+			f, line,
+		)
+	} else if err != nil && !errors.As(err, &exitErr) {
+		f, line := callerLoc()
+		FormatError(
+			c.t, nil, errChain(err),
+			fmt.Sprintf("The command could not be waited on | Cmd: %s", c),
+			f, line,
+		)
+	}
+	return c
+}
+
+// Returns the exit code of the command, waiting for it to finish if needed.
+func (c *Cmd) ExitCode() int {
+	c.t.Helper()
+	c.Wait()
+	return c.code
+}
+
+// Returns the captured stdout of the command, waiting for it to finish if
+// needed.
+func (c *Cmd) Stdout() string {
+	c.t.Helper()
+	c.Wait()
+	return c.stdout.String()
+}
+
+// Returns the captured stderr of the command, waiting for it to finish if
+// needed.
+func (c *Cmd) Stderr() string {
+	c.t.Helper()
+	c.Wait()
+	return c.stderr.String()
+}
+
+// Tests that the command exited with the expected exit code.
+func (c *Cmd) ExitCodeEq(expected int) *Cmd {
+	c.t.Helper()
+	if got := c.ExitCode(); got != expected {
+		f, line := callerLoc()
+		FormatError(
+			c.t, expected, got,
+			fmt.Sprintf(
+				"The command did not exit with the expected code | Cmd: %s%s",
+				c, c.output(),
+			),
+			f, line,
+		)
+	}
+	return c
+}
+
+// Tests that the stdout of the command is equal to the expected value. A line
+// based diff is shown on failure, or only the first differing line if the
+// output is too large to diff.
+func (c *Cmd) StdoutEq(expected string) *Cmd {
+	c.t.Helper()
+	c.outputEq("stdout", expected, c.Stdout())
+	return c
+}
+
+// Tests that the stdout of the command contains the supplied substring.
+func (c *Cmd) StdoutContains(substr string) *Cmd {
+	c.t.Helper()
+	c.outputContains("stdout", substr, c.Stdout())
+	return c
+}
+
+// Tests that the stdout of the command matches the supplied regex.
+func (c *Cmd) StdoutMatches(pattern string) *Cmd {
+	c.t.Helper()
+	c.outputMatches("stdout", pattern, c.Stdout())
+	return c
+}
+
+// Tests that the stdout of the command is equal to the contents of the golden
+// file at the supplied path. The diff is shown in the same way as
+// [Cmd.StdoutEq]. Refer to [EqGolden] for how golden files are updated.
+func (c *Cmd) StdoutGolden(path string) *Cmd {
+	c.t.Helper()
+	f, line := callerLoc()
+	eqGolden(c.t, path, c.Stdout(), f, line)
+	return c
+}
+
+// Tests that the stderr of the command is equal to the expected value. A line
+// based diff is shown on failure, or only the first differing line if the
+// output is too large to diff.
+func (c *Cmd) StderrEq(expected string) *Cmd {
+	c.t.Helper()
+	c.outputEq("stderr", expected, c.Stderr())
+	return c
+}
+
+// Tests that the stderr of the command contains the supplied substring.
+func (c *Cmd) StderrContains(substr string) *Cmd {
+	c.t.Helper()
+	c.outputContains("stderr", substr, c.Stderr())
+	return c
+}
+
+// Tests that the stderr of the command matches the supplied regex.
+func (c *Cmd) StderrMatches(pattern string) *Cmd {
+	c.t.Helper()
+	c.outputMatches("stderr", pattern, c.Stderr())
+	return c
+}
+
+// Tests that the stderr of the command is equal to the contents of the golden
+// file at the supplied path. The diff is shown in the same way as
+// [Cmd.StderrEq]. Refer to [EqGolden] for how golden files are updated.
+func (c *Cmd) StderrGolden(path string) *Cmd {
+	c.t.Helper()
+	f, line := callerLoc()
+	eqGolden(c.t, path, c.Stderr(), f, line)
+	return c
+}
+
+func (c *Cmd) outputEq(name string, expected string, got string) {
+	c.t.Helper()
+	if expected != got {
+		f, line := callerLoc()
+		FormatError(
+			c.t, expected, got,
+			fmt.Sprintf(
+				"The commands %s did not match | Cmd: %s\nDiff:%s",
+				name, c, lineDiff(expected, got),
+			),
+			f, line,
+		)
+	}
+}
+
+func (c *Cmd) outputContains(name string, substr string, got string) {
+	c.t.Helper()
+	if !strings.Contains(got, substr) {
+		f, line := callerLoc()
+		FormatError(
+			c.t, substr, got,
+			fmt.Sprintf(
+				"The commands %s did not contain the expected substring | Cmd: %s",
+				name, c,
+			),
+			f, line,
+		)
+	}
+}
+
+func (c *Cmd) outputMatches(name string, pattern string, got string) {
+	c.t.Helper()
+	if !regexp.MustCompile(pattern).MatchString(got) {
+		f, line := callerLoc()
+		FormatError(
+			c.t, pattern, got,
+			fmt.Sprintf(
+				"The regex '%s' did not match the commands %s | Cmd: %s",
+				pattern, name, c,
+			),
+			f, line,
+		)
+	}
+}
+
+func (c *Cmd) output() string {
+	return fmt.Sprintf(
+		"\nStdout: %s\nStderr: %s", c.stdout.String(), c.stderr.String(),
+	)
+}
@@ -0,0 +1,37 @@
+package sbtest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCmdLargeOutputDiff(t *testing.T) {
+	SkipWithoutBinary(t, "seq")
+	lines := make([]string, 3000)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("x%d", i+1)
+	}
+	expected := strings.Join(lines, "\n") + "\n"
+	golden := filepath.Join(t.TempDir(), "out.golden")
+	NoError(t, os.WriteFile(golden, []byte(expected), 0644))
+
+	msgs := recordFailures(t, func(t testing.TB) {
+		RunCmd(t, 10*time.Second, "seq", "3000").StdoutEq(expected)
+	})
+	Eq(t, 1, len(msgs))
+	True(t, strings.Contains(
+		msgs[0], "<too large to diff> | First Differing Line: 1",
+	))
+
+	msgs = recordFailures(t, func(t testing.TB) {
+		RunCmd(t, 10*time.Second, "seq", "3000").StdoutGolden(golden)
+	})
+	Eq(t, 1, len(msgs))
+	True(t, strings.Contains(
+		msgs[0], "<too large to diff> | First Differing Line: 1",
+	))
+}
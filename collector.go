@@ -0,0 +1,139 @@
+package sbtest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type (
+	// Collects the failures of assertions made from goroutines other than the
+	// one running the test, where calling t.Fatal is not allowed. Collector
+	// implements [testing.TB] so it can be passed to any assertion in this
+	// package from any goroutine. Any method that would normally stop the
+	// test, such as Fatal or FailNow, instead records the failure and stops
+	// the calling goroutine with [runtime.Goexit], so deferred calls such as
+	// [sync.WaitGroup.Done] still run. Skip, Skipf, and SkipNow also stop
+	// the calling goroutine, and the test is skipped by Flush if no failures
+	// were recorded. The recorded failures are reported by Flush from the
+	// goroutine running the test.
+	//
+	//	c := sbtest.NewCollector(t)
+	//	var wg sync.WaitGroup
+	//	for i := range 4 {
+	//		wg.Add(1)
+	//		go func() {
+	//			defer wg.Done()
+	//			sbtest.Eq(c, i, work(i))
+	//		}()
+	//	}
+	//	wg.Wait()
+	//	c.Flush()
+	//
+	// Any failures that have not been flushed when the test completes are
+	// reported then, at the location the collector was created. All methods
+	// are safe for concurrent use.
+	Collector struct {
+		testing.TB
+		mu  sync.Mutex
+		rec *failureRecorder
+	}
+)
+
+// Creates a new collector that will report to the supplied test.
+func NewCollector(t testing.TB) *Collector {
+	f, line := callerLoc()
+	c := &Collector{TB: t, rec: &failureRecorder{}}
+	t.Cleanup(func() {
+		t.Helper()
+		c.flush(f, line)
+	})
+	return c
+}
+
+// Records the failure and stops the calling goroutine.
+func (c *Collector) Fatal(args ...any) { c.recorder().Fatal(args...) }
+
+// Records the failure and stops the calling goroutine.
+func (c *Collector) Fatalf(format string, args ...any) {
+	c.recorder().Fatalf(format, args...)
+}
+
+// Marks the collector as failed and stops the calling goroutine.
+func (c *Collector) FailNow() { c.recorder().FailNow() }
+
+// Records the failure and continues the calling goroutine.
+func (c *Collector) Error(args ...any) { c.recorder().Error(args...) }
+
+// Records the failure and continues the calling goroutine.
+func (c *Collector) Errorf(format string, args ...any) {
+	c.recorder().Errorf(format, args...)
+}
+
+// Marks the collector as failed and continues the calling goroutine.
+func (c *Collector) Fail() { c.recorder().Fail() }
+
+// Returns true if any failure has been recorded since the last call to Flush.
+func (c *Collector) Failed() bool { return c.recorder().Failed() }
+
+// Records the skip and stops the calling goroutine.
+func (c *Collector) Skip(args ...any) { c.recorder().Skip(args...) }
+
+// Records the skip and stops the calling goroutine.
+func (c *Collector) Skipf(format string, args ...any) {
+	c.recorder().Skipf(format, args...)
+}
+
+// Records the skip and stops the calling goroutine.
+func (c *Collector) SkipNow() { c.recorder().SkipNow() }
+
+// Returns true if a skip has been recorded since the last call to Flush.
+func (c *Collector) Skipped() bool { return c.recorder().Skipped() }
+
+// Emits all the failures recorded since the last call to Flush as a single
+// failure to the underlying test and stops the test. Each failure is numbered
+// in the order it was recorded. If there were no failures but a skip was
+// recorded then the test is skipped, otherwise nothing is reported. Flush
+// must be called from the goroutine running the test, after the goroutines
+// using the collector have finished.
+func (c *Collector) Flush() {
+	c.TB.Helper()
+	f, line := callerLoc()
+	c.flush(f, line)
+}
+
+func (c *Collector) flush(f string, line int) {
+	c.TB.Helper()
+	c.mu.Lock()
+	rec := c.rec
+	c.rec = &failureRecorder{}
+	c.mu.Unlock()
+	if !rec.Failed() {
+		if rec.Skipped() {
+			c.TB.Skip(rec.SkipMsg())
+		}
+		return
+	}
+
+	msgs := rec.Msgs()
+	var sb strings.Builder
+	for i, iterMsg := range msgs {
+		fmt.Fprintf(
+			&sb, "\n%d) %s", i+1, strings.ReplaceAll(iterMsg, "\n", "\n   "),
+		)
+	}
+	FormatError(
+		c.TB, 0, len(msgs),
+		fmt.Sprintf("Assertions in goroutines failed.%s", sb.String()),
+		f, line,
+	)
+}
+
+func (c *Collector) recorder() *failureRecorder {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rec
+}
+
+func (c *Collector) wrapped() testing.TB { return c.TB }
@@ -0,0 +1,56 @@
+package sbtest
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCollectorFlush(t *testing.T) {
+	reached := false
+	msgs := recordFailures(t, func(t testing.TB) {
+		c := NewCollector(t)
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Errorf("first %d", 1)
+			c.Fatal("second")
+			reached = true
+		}()
+		wg.Wait()
+		True(t, c.Failed())
+		c.Flush()
+	})
+	False(t, reached)
+	Eq(t, 1, len(msgs))
+	True(t, strings.Contains(
+		msgs[0], "Assertions in goroutines failed.\n1) first 1\n2) second",
+	))
+	True(t, strings.Contains(msgs[0], "Got     : (int) '2'"))
+}
+
+func TestCollectorAssertions(t *testing.T) {
+	msgs := recordFailures(t, func(t testing.TB) {
+		c := NewCollector(t)
+		var wg sync.WaitGroup
+		for i := range 4 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				Eq(c, 0, i%2)
+			}()
+		}
+		wg.Wait()
+		c.Flush()
+	})
+	Eq(t, 1, len(msgs))
+	True(t, strings.Contains(msgs[0], "Assertions in goroutines failed.\n1) Error | File "))
+	Eq(t, 2, strings.Count(msgs[0], "The supplied values were not equal"))
+	True(t, strings.Contains(msgs[0], "Got     : (int) '2'"))
+
+	c := NewCollector(t)
+	Eq(c, 1, 1)
+	False(t, c.Failed())
+	c.Flush()
+}
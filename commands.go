@@ -0,0 +1,181 @@
+package sbtest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type (
+	// Describes a stateful system under test and a simplified model of it for
+	// use with [RunCommands]. S is the type of the real system and M is the
+	// type of the model.
+	Commands[S any, M any] struct {
+		// Creates a new system and the model of its initial state. Called at
+		// the start of every sequence of commands, including every sequence
+		// that is run while shrinking. Required.
+		Init func(t testing.TB) (S, M)
+		// The operations that can be performed on the system. Required.
+		Cmds []Command[S, M]
+		// Asserts that the system and the model agree. Called after every
+		// command. Optional.
+		Check func(t testing.TB, sys S, model M)
+	}
+
+	// A single operation that can be performed on a system and its model.
+	Command[S any, M any] struct {
+		// The name of the command as shown in failures.
+		Name string
+		// Returns true if the command can be run in the state described by
+		// the model. If nil the command can always be run.
+		Pre func(model M) bool
+		// Performs the operation on both the system and the model, asserting
+		// that any results agree. Random arguments for the operation should
+		// be drawn from r so they can be reproduced while shrinking.
+		Run func(t testing.TB, r *Rand, sys S, model *M)
+	}
+
+	// A single step of a sequence of commands.
+	commandStep struct {
+		cmd  int
+		seed uint64
+	}
+)
+
+// Tests a stateful system by running runs random sequences of at most maxSteps
+// commands against both the system and its model. Each step picks a random
+// command whose precondition holds in the current state of the model, and
+// after every step the Check function of c asserts the system and model still
+// agree. Randomness is drawn from a [Rand] created with [NewRand], so the same
+// sequences are run every time the test runs.
+//
+//	sbtest.RunCommands(t, 50, 20, sbtest.Commands[*Stack, []int]{
+//		Init: func(t testing.TB) (*Stack, []int) { return NewStack(), nil },
+//		Cmds: []sbtest.Command[*Stack, []int]{
+//			{
+//				Name: "Push",
+//				Run: func(t testing.TB, r *sbtest.Rand, s *Stack, m *[]int) {
+//					v := r.IntN(100)
+//					s.Push(v)
+//					*m = append(*m, v)
+//				},
+//			},
+//			{
+//				Name: "Pop",
+//				Pre:  func(m []int) bool { return len(m) > 0 },
+//				Run: func(t testing.TB, r *sbtest.Rand, s *Stack, m *[]int) {
+//					sbtest.Eq(t, (*m)[len(*m)-1], s.Pop())
+//					*m = (*m)[:len(*m)-1]
+//				},
+//			},
+//		},
+//		Check: func(t testing.TB, s *Stack, m []int) {
+//			sbtest.Eq(t, len(m), s.Len())
+//		},
+//	})
+//
+// When a sequence fails it is shrunk by removing steps while it still fails
+// and the test fails with the minimized sequence of commands. Steps keep their
+// random arguments while shrinking, and sequences in which a precondition no
+// longer holds are discarded. Cleanups registered by Init are run when the
+// test completes rather than after each sequence.
+func RunCommands[S any, M any](
+	t testing.TB,
+	runs int,
+	maxSteps int,
+	c Commands[S, M],
+) {
+	t.Helper()
+	f, line := callerLoc()
+	r := NewRand(t)
+	for i := range runs {
+		var steps []commandStep
+		failedAt := -1
+		msgs := recordFailures(t, func(t testing.TB) {
+			sys, model := c.Init(t)
+			for range maxSteps {
+				enabled := []int{}
+				for j, iterCmd := range c.Cmds {
+					if iterCmd.Pre == nil || iterCmd.Pre(model) {
+						enabled = append(enabled, j)
+					}
+				}
+				if len(enabled) == 0 {
+					return
+				}
+				step := commandStep{
+					cmd:  enabled[r.IntN(len(enabled))],
+					seed: r.Uint64(),
+				}
+				steps = append(steps, step)
+				failedAt = len(steps) - 1
+				if !c.runStep(t, step, sys, &model) {
+					return
+				}
+			}
+		})
+		if len(msgs) == 0 {
+			continue
+		}
+		minimized, shrinkSteps := Shrink(steps, func(steps []commandStep) bool {
+			valid, cur := true, -1
+			m := recordFailures(t, func(t testing.TB) {
+				sys, model := c.Init(t)
+				for j, iterStep := range steps {
+					if pre := c.Cmds[iterStep.cmd].Pre; pre != nil && !pre(model) {
+						valid = false
+						return
+					}
+					cur = j
+					if !c.runStep(t, iterStep, sys, &model) {
+						return
+					}
+				}
+			})
+			if valid && len(m) > 0 {
+				msgs, failedAt = m, cur
+				return true
+			}
+			return false
+		})
+		FormatError(
+			t, "the system to match the model",
+			fmt.Sprintf("%d failing commands", len(minimized)),
+			fmt.Sprintf(
+				"The system did not match the model | Run: %d/%d | "+
+					"Original Steps: %d | Shrink Steps: %d\nCommands:%s\nFailures:%s",
+				i+1, runs, len(steps), shrinkSteps,
+				c.fmtSteps(minimized, failedAt), fmtPropertyFailures(msgs),
+			),
+			f, line,
+		)
+		return
+	}
+}
+
+// Runs a single step followed by the check, returning false if either failed.
+func (c Commands[S, M]) runStep(
+	t testing.TB,
+	step commandStep,
+	sys S,
+	model *M,
+) bool {
+	t.Helper()
+	c.Cmds[step.cmd].Run(t, NewRandWithSeed(step.seed), sys, model)
+	if c.Check != nil && !t.Failed() {
+		c.Check(t, sys, *model)
+	}
+	return !t.Failed()
+}
+
+// Formats the names of the supplied steps, marking the step that failed.
+func (c Commands[S, M]) fmtSteps(steps []commandStep, failedAt int) string {
+	var sb strings.Builder
+	for i, iterStep := range steps {
+		fmt.Fprintf(&sb, "\n  %d) %s", i+1, c.Cmds[iterStep.cmd].Name)
+		if i == failedAt {
+			sb.WriteString(" <- failed")
+		}
+	}
+	return sb.String()
+}
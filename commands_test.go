@@ -0,0 +1,51 @@
+package sbtest
+
+import (
+	"strings"
+	"testing"
+)
+
+// A counter that wraps back to zero when it is incremented past two.
+func counterCommands() Commands[*int, int] {
+	return Commands[*int, int]{
+		Init: func(t testing.TB) (*int, int) { return new(int), 0 },
+		Cmds: []Command[*int, int]{
+			{
+				Name: "Inc",
+				Run: func(t testing.TB, r *Rand, sys *int, model *int) {
+					if *sys == 2 {
+						*sys = 0
+					} else {
+						*sys++
+					}
+					*model++
+				},
+			},
+			{
+				Name: "Get",
+				Run:  func(t testing.TB, r *Rand, sys *int, model *int) {},
+			},
+		},
+		Check: func(t testing.TB, sys *int, model int) {
+			Eq(t, model, *sys)
+		},
+	}
+}
+
+func TestRunCommandsShrinks(t *testing.T) {
+	msgs := recordFailures(t, func(t testing.TB) {
+		RunCommands(t, 50, 20, counterCommands())
+	})
+	Eq(t, 1, len(msgs))
+	True(t, strings.Contains(msgs[0], "The system did not match the model | Run: "))
+	True(t, strings.Contains(
+		msgs[0], "\nCommands:\n  1) Inc\n  2) Inc\n  3) Inc <- failed\nFailures:",
+	))
+	True(t, strings.Contains(msgs[0], "Got     : (string) '3 failing commands'"))
+}
+
+func TestRunCommandsPreconditions(t *testing.T) {
+	c := counterCommands()
+	c.Cmds[0].Pre = func(model int) bool { return model < 2 }
+	RunCommands(t, 50, 20, c)
+}
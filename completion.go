@@ -0,0 +1,76 @@
+package sbtest
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Tests that the supplied function returns before the timeout expires. The
+// function is run in a new goroutine. If it does not return in time the
+// failure contains the stacks of all other goroutines, showing where the
+// function and anything it is waiting on are blocked. The function keeps
+// running after the test fails since goroutines cannot be stopped. A panic in
+// the function fails the test with the panic value.
+func Completes(t testing.TB, timeout time.Duration, fn func()) {
+	t.Helper()
+	f, line := callerLoc()
+	done := make(chan any, 1)
+	go func() {
+		defer func() { done <- recover() }()
+		fn()
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case r := <-done:
+		if r != nil {
+			FormatError(
+				t, "returned", r,
+				fmt.Sprintf("The function panicked | Panic: %v", r),
+				f, line,
+			)
+		}
+	case <-timer.C:
+		FormatError(
+			t, "returned", "timeout",
+			fmt.Sprintf(
+				"The function did not return before the timeout expired | Timeout: %s"+
+					"\nGoroutines:\n%s",
+				timeout, goroutineStacks(),
+			),
+			f, line,
+		)
+	}
+}
+
+// Tests that the supplied wait group's counter reaches zero before the timeout
+// expires. If it does not the failure contains the stacks of all other
+// goroutines, showing which ones have not called Done.
+func WaitGroupDone(t testing.TB, wg *sync.WaitGroup, timeout time.Duration) {
+	t.Helper()
+	f, line := callerLoc()
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+	case <-timer.C:
+		FormatError(
+			t, "done wait group", "timeout",
+			fmt.Sprintf(
+				"The wait group was not done before the timeout expired | Timeout: %s"+
+					"\nGoroutines:\n%s",
+				timeout, goroutineStacks(),
+			),
+			f, line,
+		)
+	}
+}
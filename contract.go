@@ -0,0 +1,79 @@
+package sbtest
+
+import (
+	"testing"
+)
+
+type (
+	// A reusable suite of behavioral tests for implementations of an
+	// interface T. A package that defines an interface can export a contract
+	// that describes how every implementation must behave, and each
+	// implementation verifies that it conforms by running the contract with a
+	// factory that creates new instances of it.
+	//
+	//	var StoreContract = sbtest.NewContract[store.Store]().
+	//		Case("get missing key", func(t *testing.T, s store.Store) {
+	//			_, err := s.Get("a")
+	//			sbtest.ContainsError(t, store.ErrNotFound, err)
+	//		}).
+	//		Case("get after put", func(t *testing.T, s store.Store) {
+	//			sbtest.NoError(t, s.Put("a", "1"))
+	//			v, err := s.Get("a")
+	//			sbtest.NoError(t, err)
+	//			sbtest.Eq(t, "1", v)
+	//		})
+	//
+	//	func TestMemStore(t *testing.T) {
+	//		storetest.StoreContract.Run(t, func(t *testing.T) store.Store {
+	//			return NewMemStore()
+	//		})
+	//	}
+	//
+	// Contracts are immutable, Case returns a new contract and leaves the
+	// original unchanged, so a contract can be extended with cases that only
+	// apply to some implementations.
+	Contract[T any] struct {
+		cases []contractCase[T]
+	}
+
+	// A single named case of a contract.
+	contractCase[T any] struct {
+		name string
+		fn   func(t *testing.T, v T)
+	}
+)
+
+// Creates a new contract that has no cases.
+func NewContract[T any]() *Contract[T] {
+	return &Contract[T]{}
+}
+
+// Returns a new contract that has all the cases of the contract Case was
+// called on followed by the supplied case. The case is given a new instance of
+// the implementation under test each time the contract is run.
+func (c *Contract[T]) Case(
+	name string,
+	fn func(t *testing.T, v T),
+) *Contract[T] {
+	rv := *c
+	rv.cases = append(
+		append([]contractCase[T]{}, c.cases...),
+		contractCase[T]{name: name, fn: fn},
+	)
+	return &rv
+}
+
+// Runs each case of the contract as a named subtest, in the order the cases
+// were added. Each case is given a new instance of the implementation under
+// test that is created by calling the factory with the case's subtest, so the
+// factory can register cleanups and fail the case. As with [RunTableSlice]
+// every failure of an assertion in this package that is made with the subtest
+// is prefixed with the name and index of the case.
+func (c *Contract[T]) Run(t *testing.T, factory func(t *testing.T) T) {
+	for i, iterCase := range c.cases {
+		t.Run(iterCase.name, func(st *testing.T) {
+			labelCase(st, iterCase.name, i)
+			iterCase.fn(st, factory(st))
+		})
+	}
+}
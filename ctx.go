@@ -0,0 +1,64 @@
+package sbtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// Tests that the supplied context is done before the timeout expires.
+func CtxDone(t testing.TB, ctx context.Context, timeout time.Duration) {
+	t.Helper()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+		f, line := callerLoc()
+		FormatError(
+			t, "done context", "timeout",
+			fmt.Sprintf(
+				"The context was not done before the timeout expired | Timeout: %s",
+				timeout,
+			),
+			f, line,
+		)
+	}
+}
+
+// Tests that the supplied context is not done at the time of calling.
+func CtxNotDone(t testing.TB, ctx context.Context) {
+	t.Helper()
+	select {
+	case <-ctx.Done():
+		f, line := callerLoc()
+		FormatError(
+			t, nil, errChain(ctx.Err()),
+			"The context was done when it was not expected to be.",
+			f, line,
+		)
+	default:
+	}
+}
+
+// Tests that the error returned by the supplied contexts Err method contains
+// the expected error, such as [context.Canceled] or
+// [context.DeadlineExceeded]. The contexts cause is included in the failure
+// output.
+func CtxErrIs(t testing.TB, ctx context.Context, expected error) {
+	t.Helper()
+	if err := ctx.Err(); !errors.Is(err, expected) {
+		f, line := callerLoc()
+		FormatError(
+			t, expected, err,
+			fmt.Sprintf(
+				"The expected error was not contained in the contexts error | Cause: %v",
+				context.Cause(ctx),
+			),
+			f, line,
+		)
+	}
+}
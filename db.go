@@ -0,0 +1,321 @@
+package sbtest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type (
+	// The set of methods used by the database assertions in this package to
+	// run queries. This is implemented by [sql.DB], [sql.Tx], and [sql.Conn].
+	Querier interface {
+		QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	}
+)
+
+// Opens a connection to the database with the supplied driver and data source
+// name and begins a transaction that is rolled back, and the connection
+// closed, when the test completes. The setup statements, such as migrations
+// or seed data, are executed inside the transaction in the order they are
+// given. All work done through the returned transaction is therefore undone
+// once the test completes, so tests do not affect each other.
+//
+// Note that some databases implicitly commit the current transaction when
+// certain statements, such as schema changes, are executed. Refer to the
+// documentation of the database for which statements are transactional.
+func OpenTestDB(
+	t testing.TB,
+	driver string,
+	dsn string,
+	setup ...string,
+) *sql.Tx {
+	t.Helper()
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		f, line := callerLoc()
+		FormatError(
+			t, nil, errChain(err),
+			fmt.Sprintf("The database could not be opened | Driver: %s", driver),
+			f, line,
+		)
+		return nil
+	}
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		db.Close()
+		f, line := callerLoc()
+		FormatError(
+			t, nil, errChain(err),
+			fmt.Sprintf("The transaction could not be started | Driver: %s", driver),
+			f, line,
+		)
+		return nil
+	}
+	t.Cleanup(func() {
+		tx.Rollback()
+		db.Close()
+	})
+
+	for i, iterStmt := range setup {
+		if _, err := tx.Exec(iterStmt); err != nil {
+			f, line := callerLoc()
+			FormatError(
+				t, nil, errChain(err),
+				fmt.Sprintf(
+					"The setup statement failed | Index: %d | Statement: %s",
+					i, iterStmt,
+				),
+				f, line,
+			)
+			return nil
+		}
+	}
+	return tx
+}
+
+// Tests that the supplied table has the expected number of rows. The table
+// name is inserted into the query as is, so it must not come from untrusted
+// input.
+func RowCountEq(t testing.TB, q Querier, table string, expected int) {
+	t.Helper()
+	query := "SELECT COUNT(*) FROM " + table
+	got, ok := queryValue[int](t, q, query, nil)
+	if !ok {
+		return
+	}
+	if got != expected {
+		f, line := callerLoc()
+		FormatError(
+			t, expected, got,
+			fmt.Sprintf(
+				"The table did not have the expected number of rows | Table: %s",
+				table,
+			),
+			f, line,
+		)
+	}
+}
+
+// Tests that the supplied query returns exactly one row with a single column
+// that is equal to the expected value. The column is scanned into a value of
+// type T, so the rules for conversion are the same as [sql.Rows.Scan].
+func QueryReturns[T comparable](
+	t testing.TB,
+	q Querier,
+	expected T,
+	query string,
+	args ...any,
+) {
+	t.Helper()
+	got, ok := queryValue[T](t, q, query, args)
+	if !ok {
+		return
+	}
+	if got != expected {
+		f, line := callerLoc()
+		FormatError(
+			t, expected, got,
+			fmt.Sprintf(
+				"The query did not return the expected value | Query: %s",
+				query,
+			),
+			f, line,
+		)
+	}
+}
+
+func queryValue[T any](
+	t testing.TB,
+	q Querier,
+	query string,
+	args []any,
+) (T, bool) {
+	t.Helper()
+	var rv T
+	rows, ok := runQuery(t, q, query, args)
+	if !ok {
+		return rv, false
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err == nil && len(cols) != 1 {
+		f, line := callerLoc()
+		FormatError(
+			t, 1, len(cols),
+			fmt.Sprintf(
+				"The query did not return a single column | Query: %s",
+				query,
+			),
+			f, line,
+		)
+		return rv, false
+	}
+	n := 0
+	for err == nil && rows.Next() {
+		n++
+		if n == 1 {
+			err = rows.Scan(&rv)
+		}
+	}
+	if err == nil {
+		err = rows.Err()
+	}
+	if err != nil {
+		f, line := callerLoc()
+		FormatError(
+			t, nil, errChain(err),
+			fmt.Sprintf("The query results could not be read | Query: %s", query),
+			f, line,
+		)
+		return rv, false
+	}
+	if n != 1 {
+		f, line := callerLoc()
+		FormatError(
+			t, 1, n,
+			fmt.Sprintf(
+				"The query did not return a single row | Query: %s",
+				query,
+			),
+			f, line,
+		)
+		return rv, false
+	}
+	return rv, true
+}
+
+func runQuery(
+	t testing.TB,
+	q Querier,
+	query string,
+	args []any,
+) (*sql.Rows, bool) {
+	t.Helper()
+	rows, err := q.QueryContext(context.Background(), query, args...)
+	if err != nil {
+		f, line := callerLoc()
+		FormatError(
+			t, nil, errChain(err),
+			fmt.Sprintf("The query failed | Query: %s", query),
+			f, line,
+		)
+		return nil, false
+	}
+	return rows, true
+}
+
+// Tests that the supplied query returns the expected rows, in order. Each cell
+// is compared after normalizing both values: signed integers are converted to
+// int64, unsigned integers to uint64, floats to float64, and byte slices to
+// strings, so that the expected rows do not need to match the types returned
+// by a specific driver. Every cell that differs is listed on failure with its
+// row index and column name.
+func QueryEq(
+	t testing.TB,
+	q Querier,
+	query string,
+	args []any,
+	expected [][]any,
+) {
+	t.Helper()
+	rows, ok := runQuery(t, q, query, args)
+	if !ok {
+		return
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	got := [][]any{}
+	for err == nil && rows.Next() {
+		row := make([]any, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range row {
+			ptrs[i] = &row[i]
+		}
+		if err = rows.Scan(ptrs...); err == nil {
+			got = append(got, row)
+		}
+	}
+	if err == nil {
+		err = rows.Err()
+	}
+	if err != nil {
+		f, line := callerLoc()
+		FormatError(
+			t, nil, errChain(err),
+			fmt.Sprintf("The query results could not be read | Query: %s", query),
+			f, line,
+		)
+		return
+	}
+
+	var sb strings.Builder
+	if len(expected) != len(got) {
+		fmt.Fprintf(
+			&sb, "\n  The number of rows differed | Expected: %d | Got: %d",
+			len(expected), len(got),
+		)
+	}
+	for i := range min(len(expected), len(got)) {
+		if len(expected[i]) != len(got[i]) {
+			fmt.Fprintf(
+				&sb,
+				"\n  Row: %d | The number of columns differed | Expected: %d | Got: %d",
+				i, len(expected[i]), len(got[i]),
+			)
+			continue
+		}
+		for j := range got[i] {
+			e, g := normalizeCell(expected[i][j]), normalizeCell(got[i][j])
+			if !deepEqual(e, g) {
+				fmt.Fprintf(
+					&sb,
+					"\n  Row: %d | Column: %s | Expected: (%T) '%v' | Got: (%T) '%v'",
+					i, cols[j], e, e, g, g,
+				)
+			}
+		}
+	}
+	if sb.Len() > 0 {
+		f, line := callerLoc()
+		FormatError(
+			t, expected, got,
+			fmt.Sprintf(
+				"The query did not return the expected rows | Query: %s\nDifferences:%s",
+				query, sb.String(),
+			),
+			f, line,
+		)
+	}
+}
+
+func normalizeCell(v any) any {
+	switch v := v.(type) {
+	case int:
+		return int64(v)
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case uint:
+		return uint64(v)
+	case uint8:
+		return uint64(v)
+	case uint16:
+		return uint64(v)
+	case uint32:
+		return uint64(v)
+	case float32:
+		return float64(v)
+	case []byte:
+		return string(v)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,99 @@
+package sbtest
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type (
+	// A database driver that accepts every statement except those starting
+	// with BAD and records the statements and how transactions ended.
+	fakeDriver struct {
+		mu  sync.Mutex
+		log []string
+	}
+
+	fakeConn struct{ d *fakeDriver }
+	fakeStmt struct {
+		d     *fakeDriver
+		query string
+	}
+	fakeTx struct{ d *fakeDriver }
+)
+
+var testDriver = &fakeDriver{}
+
+func init() { sql.Register("sbtest-fake", testDriver) }
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) { return fakeConn{d: d}, nil }
+
+func (d *fakeDriver) record(entry string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.log = append(d.log, entry)
+}
+
+func (d *fakeDriver) entries() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string{}, d.log...)
+}
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeStmt{d: c.d, query: query}, nil
+}
+func (c fakeConn) Close() error              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error) { return fakeTx{d: c.d}, nil }
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if strings.HasPrefix(s.query, "BAD") {
+		return nil, errors.New("syntax error")
+	}
+	s.d.record(s.query)
+	return driver.RowsAffected(0), nil
+}
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, errors.New("queries are not supported")
+}
+
+func (tx fakeTx) Commit() error   { tx.d.record("COMMIT"); return nil }
+func (tx fakeTx) Rollback() error { tx.d.record("ROLLBACK"); return nil }
+
+func TestOpenTestDBRollsBack(t *testing.T) {
+	testDriver.log = nil
+	t.Run("db", func(t *testing.T) {
+		tx := OpenTestDB(t, "sbtest-fake", "", "CREATE a", "INSERT a")
+		_, err := tx.Exec("INSERT b")
+		Nil(t, err)
+	})
+	SlicesMatch(
+		t, []string{"CREATE a", "INSERT a", "INSERT b", "ROLLBACK"},
+		testDriver.entries(),
+	)
+}
+
+func TestOpenTestDBFailures(t *testing.T) {
+	msgs := recordFailures(t, func(t testing.TB) {
+		OpenTestDB(t, "sbtest-missing", "")
+	})
+	Eq(t, 1, len(msgs))
+	True(t, strings.Contains(
+		msgs[0], "The database could not be opened | Driver: sbtest-missing",
+	))
+	True(t, strings.Contains(msgs[0], "unknown driver"))
+
+	msgs = recordFailures(t, func(t testing.TB) {
+		OpenTestDB(t, "sbtest-fake", "", "CREATE a", "BAD b")
+	})
+	Eq(t, 1, len(msgs))
+	True(t, strings.Contains(
+		msgs[0], "The setup statement failed | Index: 1 | Statement: BAD b",
+	))
+	True(t, strings.Contains(msgs[0], "syntax error"))
+}
@@ -0,0 +1,164 @@
+package sbtest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The maximum number of cells in the table that lineDiff uses to find the
+// longest common subsequence of the lines that differ. Inputs that need a
+// larger table are summarized by their first differing line instead, so
+// comparing large files cannot exhaust memory.
+const maxLineDiffCells = 1 << 20
+
+// Returns a line based diff of the supplied strings. Lines that are only
+// present in expected are prefixed with '-', lines that are only present in got
+// are prefixed with '+', and lines that are present in both are prefixed with a
+// space. Lines that are common to the start or end of both strings are matched
+// directly and the diff of the remaining lines is computed with a longest
+// common subsequence search. If the remaining lines are too large to search,
+// refer to [maxLineDiffCells], only the first differing line is shown.
+func lineDiff(expected string, got string) string {
+	e := strings.Split(expected, "\n")
+	g := strings.Split(got, "\n")
+	prefix := 0
+	for prefix < len(e) && prefix < len(g) && e[prefix] == g[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(e)-prefix && suffix < len(g)-prefix &&
+		e[len(e)-1-suffix] == g[len(g)-1-suffix] {
+		suffix++
+	}
+	eMid, gMid := e[prefix:len(e)-suffix], g[prefix:len(g)-suffix]
+	if (len(eMid)+1)*(len(gMid)+1) > maxLineDiffCells {
+		return firstLineDiff(e, g, prefix)
+	}
+
+	var sb strings.Builder
+	for _, iterLine := range e[:prefix] {
+		sb.WriteString("\n  " + iterLine)
+	}
+	lcsLineDiff(&sb, eMid, gMid)
+	for _, iterLine := range e[len(e)-suffix:] {
+		sb.WriteString("\n  " + iterLine)
+	}
+	return sb.String()
+}
+
+// Writes the diff of the supplied lines, as described by lineDiff, using a
+// longest common subsequence search that needs len(e)*len(g) space.
+func lcsLineDiff(sb *strings.Builder, e []string, g []string) {
+	// lcs[i][j] holds the length of the longest common subsequence of e[i:]
+	// and g[j:]
+	lcs := make([][]int, len(e)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(g)+1)
+	}
+	for i := len(e) - 1; i >= 0; i-- {
+		for j := len(g) - 1; j >= 0; j-- {
+			if e[i] == g[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(e) && j < len(g) {
+		switch {
+		case e[i] == g[j]:
+			sb.WriteString("\n  " + e[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			sb.WriteString("\n- " + e[i])
+			i++
+		default:
+			sb.WriteString("\n+ " + g[j])
+			j++
+		}
+	}
+	for ; i < len(e); i++ {
+		sb.WriteString("\n- " + e[i])
+	}
+	for ; j < len(g); j++ {
+		sb.WriteString("\n+ " + g[j])
+	}
+}
+
+// Returns a summary of the first line at which the supplied lines differ, for
+// inputs that are too large for lineDiff to search.
+func firstLineDiff(e []string, g []string, idx int) string {
+	var sb strings.Builder
+	fmt.Fprintf(
+		&sb,
+		"\n  <too large to diff> | First Differing Line: %d | "+
+			"Expected Lines: %d | Got Lines: %d",
+		idx+1, len(e), len(g),
+	)
+	if idx < len(e) {
+		sb.WriteString("\n- " + e[idx])
+	}
+	if idx < len(g) {
+		sb.WriteString("\n+ " + g[idx])
+	}
+	return sb.String()
+}
+
+// The maximum number of mismatching indexes listed by sliceDiff before the
+// rest are summarized.
+const maxSliceDiffMismatches = 50
+
+// Returns an index aligned diff of the supplied slices. Ranges of indexes that
+// match are collapsed into a single line and indexes that do not match are
+// listed with both values. Indexes that are only present in one of the slices
+// are shown as missing in the other.
+func sliceDiff[T any](expected []T, got []T, eq func(l T, r T) bool) string {
+	var sb strings.Builder
+	mismatches := 0
+	matchStart := -1
+	flushMatches := func(end int) {
+		if matchStart < 0 {
+			return
+		}
+		if end-matchStart == 1 {
+			fmt.Fprintf(&sb, "\n  [%d] match", matchStart)
+		} else {
+			fmt.Fprintf(&sb, "\n  [%d-%d] match", matchStart, end-1)
+		}
+		matchStart = -1
+	}
+
+	n := max(len(expected), len(got))
+	for i := range n {
+		if i < len(expected) && i < len(got) && eq(expected[i], got[i]) {
+			if matchStart < 0 {
+				matchStart = i
+			}
+			continue
+		}
+		flushMatches(i)
+		mismatches++
+		if mismatches > maxSliceDiffMismatches {
+			continue
+		}
+		e, g := "<missing>", "<missing>"
+		if i < len(expected) {
+			e = fmt.Sprintf("%v", expected[i])
+		}
+		if i < len(got) {
+			g = fmt.Sprintf("%v", got[i])
+		}
+		fmt.Fprintf(&sb, "\n  [%d] Expected: %s | Got: %s", i, e, g)
+	}
+	flushMatches(n)
+	if mismatches > maxSliceDiffMismatches {
+		fmt.Fprintf(
+			&sb, "\n  ... %d more mismatching indexes",
+			mismatches-maxSliceDiffMismatches,
+		)
+	}
+	return sb.String()
+}
@@ -0,0 +1,47 @@
+package sbtest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLineDiff(t *testing.T) {
+	Eq(t, "\n  a\n- b\n+ c\n  d", lineDiff("a\nb\nd", "a\nc\nd"))
+	Eq(t, "\n  a\n+ b", lineDiff("a", "a\nb"))
+	Eq(t, "\n- a\n- b\n+ c", lineDiff("a\nb", "c"))
+	Eq(t, "\n  a\n  b", lineDiff("a\nb", "a\nb"))
+	Eq(t, "\n- x\n  a\n+ y", lineDiff("x\na", "a\ny"))
+}
+
+func TestLineDiffLargeCommonLines(t *testing.T) {
+	lines := make([]string, 100_000)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i)
+	}
+	expected := strings.Join(lines, "\n")
+	lines[50_000] = "changed"
+	diff := lineDiff(expected, strings.Join(lines, "\n"))
+	True(t, strings.Contains(diff, "\n- line 50000\n+ changed\n  line 50001"))
+	False(t, strings.Contains(diff, "too large"))
+}
+
+func TestLineDiffTooLarge(t *testing.T) {
+	e := make([]string, 2000)
+	g := make([]string, 2000)
+	for i := range e {
+		e[i] = fmt.Sprintf("e %d", i)
+		g[i] = fmt.Sprintf("g %d", i)
+	}
+	g[0] = e[0]
+	Eq(
+		t,
+		"\n  <too large to diff> | First Differing Line: 2 | "+
+			"Expected Lines: 2000 | Got Lines: 2000\n- e 1\n+ g 1",
+		lineDiff(strings.Join(e, "\n"), strings.Join(g, "\n")),
+	)
+	// Only the lines that differ need to be searched, so a large common
+	// prefix is cheap to diff.
+	diff := lineDiff(strings.Join(e, "\n"), strings.Join(append(e, g...), "\n"))
+	True(t, strings.HasSuffix(diff, "\n+ g 1999"))
+}
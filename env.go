@@ -0,0 +1,57 @@
+package sbtest
+
+import (
+	"flag"
+	"os"
+	"testing"
+)
+
+// The environment variable that [WithFlags] sets to guard against being used
+// from parallel tests.
+const withFlagsEnvKey = "SBTEST_WITH_FLAGS"
+
+// Sets the environment variable with the supplied key to the supplied value
+// for the duration of the test. The prior value, or the absence of a prior
+// value, is restored when the test and all its subtests complete. This uses
+// [testing.T.Setenv] so it will panic if called from a parallel test, or a test
+// with parallel ancestors, because the process environment is global state
+// that would otherwise be raced on.
+func WithEnv(t testing.TB, key string, value string) {
+	t.Helper()
+	t.Setenv(key, value)
+}
+
+// Sets all of the environment variables in the supplied map for the duration
+// of the test. Refer to [WithEnv] for the restoration and parallelism rules.
+func WithEnvMap(t testing.TB, env map[string]string) {
+	t.Helper()
+	for k, v := range env {
+		t.Setenv(k, v)
+	}
+}
+
+// Replaces [flag.CommandLine] with a new empty flag set and sets [os.Args] to
+// the name of the test binary followed by the supplied args for the duration
+// of the test. This allows code that defines flags on the default flag set and
+// calls [flag.Parse] to be run more than once in the same test binary without
+// panicking because a flag was redefined. The original flag set and args are
+// restored when the test and all its subtests complete. Like [WithEnv] this
+// modifies global state, so it panics if called from a parallel test, or a
+// test with parallel ancestors, and the test cannot be made parallel after it
+// is called.
+//
+// The new flag set uses [flag.ExitOnError] like the original, so parsing
+// invalid args exits the process. Use [ExitsWith] to test that behavior.
+func WithFlags(t testing.TB, args []string) {
+	t.Helper()
+	// Setting an environment variable is the only way to get the testing
+	// package to enforce that the test is not parallel. The variable is set to
+	// its current value so the environment is left unchanged.
+	t.Setenv(withFlagsEnvKey, os.Getenv(withFlagsEnvKey))
+	origFlags, origArgs := flag.CommandLine, os.Args
+	t.Cleanup(func() {
+		flag.CommandLine, os.Args = origFlags, origArgs
+	})
+	os.Args = append([]string{origArgs[0]}, args...)
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+}
@@ -0,0 +1,30 @@
+package sbtest
+
+import (
+	"flag"
+	"os"
+	"testing"
+)
+
+func TestWithFlags(t *testing.T) {
+	orig := flag.CommandLine
+	t.Run("replaced", func(t *testing.T) {
+		WithFlags(t, []string{"-n", "3"})
+		n := flag.Int("n", 0, "")
+		flag.Parse()
+		Eq(t, 3, *n)
+		SlicesMatch(t, []string{"-n", "3"}, os.Args[1:])
+	})
+	True(t, flag.CommandLine == orig)
+	_, set := os.LookupEnv(withFlagsEnvKey)
+	False(t, set)
+}
+
+func TestWithFlagsParallel(t *testing.T) {
+	t.Run("parallel", func(t *testing.T) {
+		t.Parallel()
+		orig := flag.CommandLine
+		Panics(t, func() { WithFlags(t, nil) })
+		True(t, flag.CommandLine == orig)
+	})
+}
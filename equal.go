@@ -0,0 +1,461 @@
+package sbtest
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+type (
+	// A pair of references that have already been compared by deepEqual,
+	// used to stop the comparison of cyclic values.
+	visit struct {
+		x   unsafe.Pointer
+		y   unsafe.Pointer
+		typ reflect.Type
+	}
+)
+
+// Tests that the supplied values are equal according to the Equal method of
+// expected. Many types, such as [time.Time] and decimal or other value object
+// types, define what it means for two values to be equal with an Equal method
+// and do not behave correctly with == or [reflect.DeepEqual].
+//
+// The deep comparisons made by other assertions in this package, such as
+// [PanicsWithValue] and [MatchNested], follow the conventions of go-cmp and
+// prefer Equal methods in the same way. Any value of type T with a method of
+// the form (T) Equal(T) bool, or (T) Equal(I) bool where T is assignable to I,
+// is compared with that method instead of field by field.
+func EqEqualer[T interface{ Equal(T) bool }](t testing.TB, expected T, got T) {
+	t.Helper()
+	if !expected.Equal(got) {
+		f, line := callerLoc()
+		FormatError(
+			t, expected, got,
+			"The supplied values were not equal according to their Equal method but were expected to be.",
+			f, line,
+		)
+	}
+}
+
+// Reports whether the supplied values are deeply equal. This follows the same
+// rules as [reflect.DeepEqual] with one exception that matches the
+// conventions of go-cmp: if a value of type T has a method of the form
+// (T) Equal(T) bool, or (T) Equal(I) bool where T is assignable to I, then
+// that method is used to compare it instead of comparing it field by field.
+// Equal methods on unexported struct fields and nil pointers are not called.
+func deepEqual(x any, y any) bool {
+	_, ok := firstDiff(x, y, walkOpts{})
+	return ok
+}
+
+type (
+	// Controls how firstDiff walks the values it compares.
+	walkOpts struct {
+		// Called with the path of every struct field before it is compared.
+		// Fields for which this returns true are not compared.
+		skipField func(path string, field reflect.StructField) bool
+		// Called with the path of every value that has an Equal method. The
+		// Equal method is only used if this is nil or returns true.
+		useEqual func(path string) bool
+	}
+
+	// The first difference found by firstDiff.
+	valueDiff struct {
+		// The path to the differing value from the root value, made of field
+		// names separated by dots and slice, array, and map indexes in
+		// square brackets, for example Items[2].Name. The path is empty when
+		// the root values differ.
+		path     string
+		expected reflect.Value
+		got      reflect.Value
+	}
+)
+
+// Returns the first difference between the supplied values, walking them using
+// the rules described on deepEqual. The second return value is true if there
+// is no difference.
+func firstDiff(x any, y any, opts walkOpts) (valueDiff, bool) {
+	if x == nil || y == nil {
+		return valueDiff{
+			expected: reflect.ValueOf(x),
+			got:      reflect.ValueOf(y),
+		}, x == y
+	}
+	return firstValueDiff(
+		reflect.ValueOf(x), reflect.ValueOf(y), "", opts, map[visit]struct{}{},
+	)
+}
+
+func firstValueDiff(
+	x reflect.Value,
+	y reflect.Value,
+	path string,
+	opts walkOpts,
+	visited map[visit]struct{},
+) (valueDiff, bool) {
+	diff := valueDiff{path: path, expected: x, got: y}
+	if !x.IsValid() || !y.IsValid() {
+		return diff, x.IsValid() == y.IsValid()
+	}
+	if x.Type() != y.Type() {
+		return diff, false
+	}
+	if opts.useEqual == nil || opts.useEqual(path) {
+		if eq, ok := callEqualMethod(x, y); ok {
+			return diff, eq
+		}
+	}
+
+	switch x.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		if x.IsNil() || y.IsNil() {
+			return diff, x.IsNil() == y.IsNil()
+		}
+		if x.UnsafePointer() == y.UnsafePointer() &&
+			(x.Kind() != reflect.Slice || x.Len() == y.Len()) {
+			return diff, true
+		}
+		v := visit{x: x.UnsafePointer(), y: y.UnsafePointer(), typ: x.Type()}
+		if _, ok := visited[v]; ok {
+			return diff, true
+		}
+		visited[v] = struct{}{}
+	}
+
+	switch x.Kind() {
+	case reflect.Array, reflect.Slice:
+		if x.Len() != y.Len() {
+			return diff, false
+		}
+		for i := range x.Len() {
+			d, ok := firstValueDiff(
+				x.Index(i), y.Index(i), fmt.Sprintf("%s[%d]", path, i),
+				opts, visited,
+			)
+			if !ok {
+				return d, false
+			}
+		}
+		return diff, true
+	case reflect.Interface:
+		if x.IsNil() || y.IsNil() {
+			return diff, x.IsNil() == y.IsNil()
+		}
+		return firstValueDiff(x.Elem(), y.Elem(), path, opts, visited)
+	case reflect.Pointer:
+		return firstValueDiff(x.Elem(), y.Elem(), path, opts, visited)
+	case reflect.Struct:
+		for i := range x.NumField() {
+			fieldPath := x.Type().Field(i).Name
+			if path != "" {
+				fieldPath = path + "." + fieldPath
+			}
+			if opts.skipField != nil &&
+				opts.skipField(fieldPath, x.Type().Field(i)) {
+				continue
+			}
+			d, ok := firstValueDiff(
+				x.Field(i), y.Field(i), fieldPath, opts, visited,
+			)
+			if !ok {
+				return d, false
+			}
+		}
+		return diff, true
+	case reflect.Map:
+		if x.Len() != y.Len() {
+			return diff, false
+		}
+		iter := x.MapRange()
+		for iter.Next() {
+			keyPath := fmt.Sprintf("%s[%v]", path, fmtReflect(iter.Key()))
+			yVal := y.MapIndex(iter.Key())
+			if !yVal.IsValid() {
+				return valueDiff{path: keyPath, expected: iter.Value()}, false
+			}
+			d, ok := firstValueDiff(iter.Value(), yVal, keyPath, opts, visited)
+			if !ok {
+				return d, false
+			}
+		}
+		return diff, true
+	case reflect.Func:
+		return diff, x.IsNil() && y.IsNil()
+	case reflect.Bool:
+		return diff, x.Bool() == y.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return diff, x.Int() == y.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return diff, x.Uint() == y.Uint()
+	case reflect.Float32, reflect.Float64:
+		return diff, x.Float() == y.Float()
+	case reflect.Complex64, reflect.Complex128:
+		return diff, x.Complex() == y.Complex()
+	case reflect.String:
+		return diff, x.String() == y.String()
+	case reflect.Chan, reflect.UnsafePointer:
+		return diff, x.Pointer() == y.Pointer()
+	default:
+		return diff, false
+	}
+}
+
+// Calls the Equal method of x with y if x has an Equal method that can be
+// used for comparison. The second return value is false if there is no such
+// method.
+func callEqualMethod(x reflect.Value, y reflect.Value) (bool, bool) {
+	if !x.CanInterface() || !y.CanInterface() {
+		return false, false
+	}
+	switch x.Kind() {
+	case reflect.Interface:
+		// Interface values are compared by their dynamic values instead
+		return false, false
+	case reflect.Pointer:
+		if x.IsNil() || y.IsNil() {
+			return false, false
+		}
+	}
+	m, ok := x.Type().MethodByName("Equal")
+	if !ok {
+		return false, false
+	}
+	mt := m.Type
+	if mt.NumIn() != 2 || mt.NumOut() != 1 || mt.Out(0).Kind() != reflect.Bool ||
+		!y.Type().AssignableTo(mt.In(1)) {
+		return false, false
+	}
+	return m.Func.Call([]reflect.Value{x, y})[0].Bool(), true
+}
+
+// Tests that the supplied values are deeply equal while skipping the struct
+// fields named by the supplied field paths. This is useful for models that
+// have fields set by a database, such as IDs or timestamps. A field path is
+// made of field names separated by dots starting from the root value, for
+// example "CreatedAt" or "Owner.ID". Slices, arrays, maps, and pointers are
+// passed through without adding to the path, so "Items.ID" skips the ID field
+// of every element of Items. The test fails if a field path does not name a
+// field of the type of expected, which guards against typos.
+//
+// Values are otherwise compared in the same way as [MatchNested], except that
+// Equal methods are not used on the root value or on any value that contains
+// a skipped field. On failure the path of the first differing value is
+// reported.
+func EqExcept(t testing.TB, expected any, got any, fieldPaths ...string) {
+	t.Helper()
+	f, line := callerLoc()
+	skip := map[string]struct{}{}
+	for _, iterPath := range fieldPaths {
+		if !fieldPathExists(reflect.TypeOf(expected), iterPath) {
+			FormatError(
+				t, "an existing field", iterPath,
+				fmt.Sprintf(
+					"The field path did not name a field of the expected type | Type: %T",
+					expected,
+				),
+				f, line,
+			)
+			return
+		}
+		skip[iterPath] = struct{}{}
+	}
+
+	d, ok := firstDiff(expected, got, walkOpts{
+		skipField: func(path string, _ reflect.StructField) bool {
+			_, ok := skip[stripPathIndexes(path)]
+			return ok
+		},
+		useEqual: func(path string) bool {
+			if path == "" {
+				return false
+			}
+			path = stripPathIndexes(path)
+			for iterSkip := range skip {
+				// An empty path is an element of a root slice, array, or map,
+				// which contains every skipped field.
+				if path == "" || strings.HasPrefix(iterSkip, path+".") {
+					return false
+				}
+			}
+			return true
+		},
+	})
+	if !ok {
+		reportValueDiff(t, d, f, line)
+	}
+}
+
+// Tests that the supplied values are deeply equal while only comparing the
+// exported fields of structs. This allows values that contain unexported
+// mutexes, caches, or other internal state to be compared without spurious
+// mismatches. Values are otherwise compared in the same way as [MatchNested],
+// so nested values with an Equal method, such as [time.Time], are compared
+// with it even though their fields are unexported. The Equal method of the
+// root value is not used. On failure the path of the first differing value is
+// reported.
+func EqExportedFields(t testing.TB, expected any, got any) {
+	t.Helper()
+	d, ok := firstDiff(expected, got, walkOpts{
+		skipField: func(_ string, field reflect.StructField) bool {
+			return !field.IsExported()
+		},
+		useEqual: func(path string) bool { return path != "" },
+	})
+	if !ok {
+		f, line := callerLoc()
+		reportValueDiff(t, d, f, line)
+	}
+}
+
+// Tests that the listed fields of the supplied value are equal to the expected
+// values, ignoring every other field. This lets a test pin down the few fields
+// it cares about on a large struct. The keys of want are field paths made of
+// field names separated by dots, for example "Name" or "Owner.ID", and
+// pointers are followed when resolving them. Each field is compared with its
+// expected value in the same way as [MatchNested], so the expected value must
+// have the same type as the field, and a nil expected value matches any nil
+// field. All fields that do not match, or that do not exist, are listed on
+// failure.
+func MatchFields(t testing.TB, got any, want map[string]any) {
+	t.Helper()
+	paths := make([]string, 0, len(want))
+	for iterPath := range want {
+		paths = append(paths, iterPath)
+	}
+	slices.Sort(paths)
+
+	var sb strings.Builder
+	for _, iterPath := range paths {
+		val, err := resolveFieldPath(reflect.ValueOf(got), iterPath)
+		if err != "" {
+			fmt.Fprintf(&sb, "\n  Path: %s | %s", iterPath, err)
+			continue
+		}
+		if !fieldMatches(want[iterPath], val) {
+			gotVal := fmtReflect(val)
+			fmt.Fprintf(
+				&sb, "\n  Path: %s | Expected: (%T) '%v' | Got: (%T) '%v'",
+				iterPath, want[iterPath], want[iterPath], gotVal, gotVal,
+			)
+		}
+	}
+	if sb.Len() > 0 {
+		f, line := callerLoc()
+		FormatError(
+			t, want, got,
+			"The fields of the supplied value did not match.\nDifferences:"+sb.String(),
+			f, line,
+		)
+	}
+}
+
+// Returns true if the supplied field value is equal to the expected value. An
+// expected value of nil matches nil pointers, slices, maps, interfaces,
+// channels, and functions.
+func fieldMatches(expected any, field reflect.Value) bool {
+	if expected == nil {
+		switch field.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface,
+			reflect.Chan, reflect.Func:
+			return field.IsNil()
+		}
+		return false
+	}
+	_, ok := firstValueDiff(
+		reflect.ValueOf(expected), field, "", walkOpts{}, map[visit]struct{}{},
+	)
+	return ok
+}
+
+// Returns the value of the field named by the supplied dotted path, following
+// pointers and interfaces. If the field cannot be reached a description of
+// the reason is returned instead.
+func resolveFieldPath(v reflect.Value, path string) (reflect.Value, string) {
+	for _, iterName := range strings.Split(path, ".") {
+		for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return v, fmt.Sprintf("Nil value before field: %s", iterName)
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return v, fmt.Sprintf(
+				"Not a struct before field: %s | Kind: %s", iterName, v.Kind(),
+			)
+		}
+		v = v.FieldByName(iterName)
+		if !v.IsValid() {
+			return v, fmt.Sprintf("Field does not exist: %s", iterName)
+		}
+	}
+	return v, ""
+}
+
+func reportValueDiff(t testing.TB, d valueDiff, f string, line int) {
+	t.Helper()
+	path := d.path
+	if path == "" {
+		path = "<root>"
+	}
+	FormatError(
+		t, fmtReflect(d.expected), fmtReflect(d.got),
+		fmt.Sprintf("Values do not match | Path: %s", path),
+		f, line,
+	)
+}
+
+// Returns the supplied value path with all slice, array, and map indexes
+// removed, for example Items[2].Name becomes Items.Name. The leading dot that
+// is left when the root value is a slice, array, or map is also removed, so
+// [0].ID becomes ID.
+func stripPathIndexes(path string) string {
+	var sb strings.Builder
+	depth := 0
+	for _, iterChar := range path {
+		switch {
+		case iterChar == '[':
+			depth++
+		case iterChar == ']' && depth > 0:
+			depth--
+		case depth == 0:
+			sb.WriteRune(iterChar)
+		}
+	}
+	return strings.TrimPrefix(sb.String(), ".")
+}
+
+// Returns true if the supplied field path, as described by [EqExcept], names a
+// field that is reachable from the supplied type.
+func fieldPathExists(typ reflect.Type, path string) bool {
+	for _, iterName := range strings.Split(path, ".") {
+		typ = derefType(typ)
+		if typ == nil || typ.Kind() != reflect.Struct {
+			return false
+		}
+		field, ok := typ.FieldByName(iterName)
+		if !ok {
+			return false
+		}
+		typ = field.Type
+	}
+	return true
+}
+
+// Returns the type that is reached by passing through all pointers, slices,
+// arrays, and map values of the supplied type.
+func derefType(typ reflect.Type) reflect.Type {
+	for typ != nil {
+		switch typ.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+			typ = typ.Elem()
+		default:
+			return typ
+		}
+	}
+	return nil
+}
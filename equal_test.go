@@ -0,0 +1,70 @@
+package sbtest
+
+import (
+	"strings"
+	"testing"
+)
+
+type eqExceptUser struct {
+	ID   int
+	Name string
+}
+
+func TestEqExceptRootContainers(t *testing.T) {
+	msgs := recordFailures(t, func(t testing.TB) {
+		EqExcept(
+			t,
+			[]eqExceptUser{{ID: 1, Name: "a"}},
+			[]eqExceptUser{{ID: 2, Name: "a"}},
+			"ID",
+		)
+		EqExcept(
+			t,
+			[1]eqExceptUser{{ID: 1, Name: "a"}},
+			[1]eqExceptUser{{ID: 2, Name: "a"}},
+			"ID",
+		)
+		EqExcept(
+			t,
+			map[string]eqExceptUser{"a": {ID: 1, Name: "a"}},
+			map[string]eqExceptUser{"a": {ID: 2, Name: "a"}},
+			"ID",
+		)
+	})
+	Eq(t, 0, len(msgs))
+}
+
+func TestEqExceptRootContainersDiffer(t *testing.T) {
+	msgs := recordFailures(t, func(t testing.TB) {
+		EqExcept(
+			t,
+			[]eqExceptUser{{ID: 1, Name: "a"}},
+			[]eqExceptUser{{ID: 2, Name: "b"}},
+			"ID",
+		)
+	})
+	Eq(t, 1, len(msgs))
+	True(t, strings.Contains(msgs[0], "Path: [0].Name"))
+}
+
+func TestEqExceptNestedPath(t *testing.T) {
+	type owner struct {
+		Users []eqExceptUser
+	}
+	msgs := recordFailures(t, func(t testing.TB) {
+		EqExcept(
+			t,
+			owner{Users: []eqExceptUser{{ID: 1, Name: "a"}}},
+			owner{Users: []eqExceptUser{{ID: 2, Name: "a"}}},
+			"Users.ID",
+		)
+	})
+	Eq(t, 0, len(msgs))
+}
+
+func TestStripPathIndexes(t *testing.T) {
+	Eq(t, "ID", stripPathIndexes("[0].ID"))
+	Eq(t, "ID", stripPathIndexes("[\"a\"].ID"))
+	Eq(t, "Items.Name", stripPathIndexes("Items[2].Name"))
+	Eq(t, "", stripPathIndexes("[0]"))
+}
@@ -0,0 +1,114 @@
+package sbtest
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type (
+	// Records named milestones from concurrent code in the order they
+	// happen, so tests can assert on the relative ordering of the milestones
+	// with [OrderedEvents] and [EventBefore] instead of relying on sleeps.
+	// The order is the order in which calls to Record acquire the recorder's
+	// lock, so an event recorded before a synchronizing operation, such as a
+	// channel send, is always ordered before an event recorded after the
+	// matching receive. All methods are safe for concurrent use.
+	//
+	//	ev := sbtest.NewEvents()
+	//	go func() {
+	//		ev.Record("start")
+	//		...
+	//		ev.Record("stop")
+	//	}()
+	//	...
+	//	sbtest.OrderedEvents(t, ev, "init", "start", "stop")
+	Events struct {
+		mu     sync.Mutex
+		events []string
+	}
+)
+
+// Creates a new, empty event recorder.
+func NewEvents() *Events {
+	return &Events{}
+}
+
+// Records that the named event happened.
+func (e *Events) Record(name string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = append(e.events, name)
+}
+
+// Returns a copy of the names of all the recorded events, in the order they
+// were recorded.
+func (e *Events) Recorded() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return slices.Clone(e.events)
+}
+
+// Tests that the supplied events were recorded in the supplied order. Other
+// events may be recorded between them, the named events only need to appear
+// in order within all the recorded events.
+func OrderedEvents(t testing.TB, rec *Events, events ...string) {
+	t.Helper()
+	recorded := rec.Recorded()
+	i := 0
+	for _, iterEvent := range recorded {
+		if i < len(events) && iterEvent == events[i] {
+			i++
+		}
+	}
+	if i < len(events) {
+		f, line := callerLoc()
+		FormatError(
+			t, events, recorded,
+			fmt.Sprintf(
+				"The events were not recorded in the expected order | "+
+					"First Unmatched: %s\nRecorded: %s",
+				events[i], fmtEvents(recorded),
+			),
+			f, line,
+		)
+	}
+}
+
+// Tests that the first time the before event was recorded was before the first
+// time the after event was recorded. Both events must have been recorded.
+func EventBefore(t testing.TB, rec *Events, before string, after string) {
+	t.Helper()
+	recorded := rec.Recorded()
+	b := slices.Index(recorded, before)
+	a := slices.Index(recorded, after)
+	if b < 0 || a < 0 || b > a {
+		f, line := callerLoc()
+		FormatError(
+			t, fmt.Sprintf("%s before %s", before, after), fmtEvents(recorded),
+			fmt.Sprintf(
+				"The event was not recorded before the other event | "+
+					"Before: %s (%s) | After: %s (%s)\nRecorded: %s",
+				before, fmtEventIndex(b), after, fmtEventIndex(a),
+				fmtEvents(recorded),
+			),
+			f, line,
+		)
+	}
+}
+
+func fmtEvents(events []string) string {
+	if len(events) == 0 {
+		return "<no events>"
+	}
+	return strings.Join(events, " -> ")
+}
+
+func fmtEventIndex(i int) string {
+	if i < 0 {
+		return "not recorded"
+	}
+	return fmt.Sprintf("index %d", i)
+}
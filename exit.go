@@ -0,0 +1,147 @@
+package sbtest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+type (
+	// The result of running a function in a subprocess with [ExitsWith] or
+	// [RunCLI].
+	ExitResult struct {
+		Code   int
+		Stdout string
+		Stderr string
+		// True if the result was returned inside a subprocess that was
+		// started for a different call, in which case the assertion methods
+		// do nothing.
+		inSubprocess bool
+	}
+)
+
+const (
+	// The environment variable that tells a re-executed test binary which
+	// call to [ExitsWith] it should run the function for.
+	exitsWithEnvVar = "SBTEST_EXITS_WITH"
+	// Written to stderr by the subprocess if the function returns without
+	// calling os.Exit.
+	exitsWithReturnedMarker = "sbtest: subprocess function returned without exiting"
+)
+
+// Tests that the supplied function exits the process with the expected exit
+// code. This allows code paths that call [os.Exit] or [log.Fatal] to be
+// tested. The captured stdout and stderr of the function are returned so
+// further assertions can be made on them.
+//
+// This works by re-executing the test binary in a subprocess that only runs
+// the current test. When the subprocess reaches this call it runs fn instead
+// of starting another subprocess. This has several consequences:
+//   - All code in the test, and in any parent tests, before the call to
+//     ExitsWith is run again in the subprocess, so it should not have side
+//     effects outside the process.
+//   - Calls are identified by their file and line, so ExitsWith should not be
+//     called more than once from the same line, for example in a loop.
+//   - Other calls to ExitsWith in the subprocess do nothing and return a zero
+//     [ExitResult], so assertions on their results will fail in the
+//     subprocess. Each call to ExitsWith should be placed in its own test or
+//     subtest, and parent tests should not call ExitsWith themselves.
+//
+// A function that returns without exiting fails the test.
+func ExitsWith(t testing.TB, expectedCode int, fn func()) ExitResult {
+	t.Helper()
+	f, line := callerLoc()
+	rv, ok := runInSubprocess(t, fn, "", f, line)
+	if !ok {
+		return rv
+	}
+
+	if stripped, ok := strings.CutSuffix(
+		rv.Stderr, exitsWithReturnedMarker+"\n",
+	); ok {
+		rv.Stderr = stripped
+		FormatError(
+			t, expectedCode, "no exit",
+			fmt.Sprintf(
+				"The supplied function returned without exiting.\nStdout: %s\nStderr: %s",
+				rv.Stdout, rv.Stderr,
+			),
+			f, line,
+		)
+		return rv
+	}
+	if rv.Code != expectedCode {
+		FormatError(
+			t, expectedCode, rv.Code,
+			fmt.Sprintf(
+				"The supplied function did not exit with the expected code.\nStdout: %s\nStderr: %s",
+				rv.Stdout, rv.Stderr,
+			),
+			f, line,
+		)
+	}
+	return rv
+}
+
+// Runs fn in a re-executed copy of the test binary that only runs the current
+// test, using the supplied stdin. The call is identified by the supplied file
+// and line. Returns false if the subprocess could not be run, or if the
+// current process is itself a subprocess that was started for a different
+// call. If fn returns without exiting the subprocess exits with a code of zero
+// and stderr ends with [exitsWithReturnedMarker].
+func runInSubprocess(
+	t testing.TB,
+	fn func(),
+	stdin string,
+	f string,
+	line int,
+) (ExitResult, bool) {
+	t.Helper()
+	key := fmt.Sprintf("%s:%d", f, line)
+	if val, ok := os.LookupEnv(exitsWithEnvVar); ok {
+		if val == key {
+			fn()
+			fmt.Fprintln(os.Stderr, exitsWithReturnedMarker)
+			os.Exit(0)
+		}
+		return ExitResult{inSubprocess: true}, false
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run="+testRunPattern(t.Name()))
+	cmd.Env = append(os.Environ(), exitsWithEnvVar+"="+key)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		FormatError(
+			t, nil, errChain(err),
+			"The subprocess could not be started.",
+			f, line,
+		)
+		return ExitResult{}, false
+	}
+	return ExitResult{
+		Code:   cmd.ProcessState.ExitCode(),
+		Stdout: stdout.String(),
+		Stderr: stderr.String(),
+	}, true
+}
+
+// Returns a -test.run pattern that matches only the test with the supplied
+// name, including all of its parent tests if it is a subtest.
+func testRunPattern(name string) string {
+	parts := strings.Split(name, "/")
+	for i, iterPart := range parts {
+		parts[i] = "^" + regexp.QuoteMeta(iterPart) + "$"
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,84 @@
+package sbtest
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+type (
+	// Builds values of type T for use as test data. A factory starts from a
+	// defaults function and is customized by deriving new factories with
+	// With and AfterBuild, so the struct literal for a type only needs to be
+	// written once.
+	//
+	//	func NewUserFactory() *sbtest.Factory[User] {
+	//		return sbtest.NewFactory(func(seq int) User {
+	//			return User{Name: fmt.Sprintf("user%d", seq)}
+	//		})
+	//	}
+	//
+	//	admin := NewUserFactory().With(func(u *User) { u.Admin = true }).Build(t)
+	//
+	// Factories are immutable, With and AfterBuild return a new factory and
+	// leave the original unchanged. All factories derived from the same call
+	// to [NewFactory] share a sequence counter. All methods are safe for
+	// concurrent use.
+	Factory[T any] struct {
+		defaults func(seq int) T
+		seq      *atomic.Int64
+		mods     []func(v *T)
+		hooks    []func(t testing.TB, v *T)
+	}
+)
+
+// Creates a new factory that uses the supplied function to create the default
+// value for each build. The function is given a sequence number that starts at
+// one and is incremented on every build, which can be used to give each value
+// unique fields such as IDs or email addresses.
+func NewFactory[T any](defaults func(seq int) T) *Factory[T] {
+	return &Factory[T]{defaults: defaults, seq: &atomic.Int64{}}
+}
+
+// Returns a new factory that applies the supplied overrides, in order, to every
+// value it builds. Overrides are applied after the defaults and after any
+// overrides of the factory With was called on.
+func (f *Factory[T]) With(overrides ...func(v *T)) *Factory[T] {
+	rv := *f
+	rv.mods = append(append([]func(v *T){}, f.mods...), overrides...)
+	return &rv
+}
+
+// Returns a new factory that calls the supplied hook on every value it builds
+// after all overrides have been applied. Hooks are intended for setting up
+// associations, such as building a related value with another factory or
+// inserting the value into a test database, and can use t to fail the test.
+// Hooks are run in the order they were added.
+func (f *Factory[T]) AfterBuild(hook func(t testing.TB, v *T)) *Factory[T] {
+	rv := *f
+	rv.hooks = append(append([]func(t testing.TB, v *T){}, f.hooks...), hook)
+	return &rv
+}
+
+// Builds a single value by calling the defaults function with the next
+// sequence number, applying all overrides, and then running all hooks.
+func (f *Factory[T]) Build(t testing.TB) T {
+	t.Helper()
+	rv := f.defaults(int(f.seq.Add(1)))
+	for _, iterMod := range f.mods {
+		iterMod(&rv)
+	}
+	for _, iterHook := range f.hooks {
+		iterHook(t, &rv)
+	}
+	return rv
+}
+
+// Builds n values, refer to [Factory.Build] for how each value is built.
+func (f *Factory[T]) BuildN(t testing.TB, n int) []T {
+	t.Helper()
+	rv := make([]T, n)
+	for i := range rv {
+		rv[i] = f.Build(t)
+	}
+	return rv
+}
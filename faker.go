@@ -0,0 +1,195 @@
+package sbtest
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type (
+	// A source of random values whose seed is derived from the name of the
+	// test, so every run of a test sees the same values while different tests
+	// see different values. The seed is logged when the test fails and can be
+	// overridden with the environment variable named by [SeedEnvVar] to
+	// reproduce a failure exactly. The embedded [rand.Rand] provides the usual
+	// random number methods. A Rand is not safe for concurrent use.
+	Rand struct {
+		*rand.Rand
+		seed uint64
+	}
+
+	// Generates fake but realistic looking data such as names, email
+	// addresses, and IP addresses. The data is derived from a [Rand], so it
+	// is reproducible in the same way. A Faker is not safe for concurrent use.
+	Faker struct {
+		*Rand
+	}
+)
+
+// Setting this environment variable to an unsigned integer overrides the seed
+// of every [Rand] and [Faker], which allows a failure to be reproduced using
+// the seed that was logged when it occurred.
+//
+//	SBTEST_SEED=1234 go test -run TestName ./...
+const SeedEnvVar = "SBTEST_SEED"
+
+var (
+	fakerFirstNames = []string{
+		"Ada", "Alan", "Barbara", "Dennis", "Donald", "Edsger", "Frances",
+		"Grace", "Hedy", "John", "Ken", "Linus", "Margaret", "Niklaus",
+		"Radia", "Rob", "Sophie", "Tim", "Tony", "Yukihiro",
+	}
+	fakerLastNames = []string{
+		"Allen", "Berners-Lee", "Dijkstra", "Hamilton", "Hopper", "Kay",
+		"Knuth", "Lamarr", "Liskov", "Lovelace", "Matsumoto", "Perlman",
+		"Pike", "Ritchie", "Shannon", "Thompson", "Torvalds", "Turing",
+		"Wilson", "Wirth",
+	}
+	fakerWords = []string{
+		"alpha", "bright", "cloud", "delta", "engine", "forest", "garden",
+		"harbor", "island", "jungle", "kernel", "lantern", "meadow", "north",
+		"orbit", "pixel", "quartz", "river", "signal", "timber", "upper",
+		"valley", "window", "yellow", "zephyr",
+	}
+	fakerDomains = []string{"example.com", "example.net", "example.org"}
+
+	// The tests that already log their seed on failure, so creating several
+	// random sources in one test only logs the seed once.
+	loggedSeeds sync.Map
+)
+
+// Creates a new random source for the supplied test. The seed is a hash of the
+// name of the test unless it is overridden by the environment variable named
+// by [SeedEnvVar]. The seed is logged once if the test fails.
+func NewRand(t testing.TB) *Rand {
+	t.Helper()
+	seed := seedFromName(t.Name())
+	if val := os.Getenv(SeedEnvVar); val != "" {
+		if s, err := strconv.ParseUint(val, 10, 64); err == nil {
+			seed = s
+		}
+	}
+	if _, loaded := loggedSeeds.LoadOrStore(t, struct{}{}); !loaded {
+		t.Cleanup(func() {
+			loggedSeeds.Delete(t)
+			if t.Failed() {
+				t.Logf(
+					"sbtest: random seed for %s was %d, set %s=%d to reproduce",
+					t.Name(), seed, SeedEnvVar, seed,
+				)
+			}
+		})
+	}
+	return NewRandWithSeed(seed)
+}
+
+// Creates a new random source with the supplied seed. Unlike [NewRand] the
+// seed is not logged on failure.
+func NewRandWithSeed(seed uint64) *Rand {
+	return &Rand{
+		Rand: rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15)),
+		seed: seed,
+	}
+}
+
+// Returns the seed the random source was created with.
+func (r *Rand) Seed() uint64 {
+	return r.seed
+}
+
+// Creates a new faker for the supplied test. Refer to [NewRand] for how the
+// seed is chosen.
+func NewFaker(t testing.TB) *Faker {
+	t.Helper()
+	return &Faker{Rand: NewRand(t)}
+}
+
+// Returns a random first name.
+func (f *Faker) FirstName() string {
+	return fakerFirstNames[f.IntN(len(fakerFirstNames))]
+}
+
+// Returns a random last name.
+func (f *Faker) LastName() string {
+	return fakerLastNames[f.IntN(len(fakerLastNames))]
+}
+
+// Returns a random full name made of a first and last name.
+func (f *Faker) Name() string {
+	return f.FirstName() + " " + f.LastName()
+}
+
+// Returns a random email address in one of the domains reserved for
+// documentation by RFC 2606, so it can never belong to a real person.
+func (f *Faker) Email() string {
+	return fmt.Sprintf(
+		"%s.%s%d@%s",
+		strings.ToLower(f.FirstName()),
+		strings.ToLower(f.LastName()),
+		f.IntN(1000),
+		fakerDomains[f.IntN(len(fakerDomains))],
+	)
+}
+
+// Returns a random version 4 UUID in its canonical string form.
+func (f *Faker) UUID() string {
+	var b [16]byte
+	for i := range b {
+		b[i] = byte(f.UintN(256))
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf(
+		"%x-%x-%x-%x-%x",
+		b[0:4], b[4:6], b[6:8], b[8:10], b[10:],
+	)
+}
+
+// Returns a random lower case word.
+func (f *Faker) Word() string {
+	return fakerWords[f.IntN(len(fakerWords))]
+}
+
+// Returns a random sentence of the supplied number of words that starts with
+// a capital letter and ends with a period.
+func (f *Faker) Sentence(words int) string {
+	parts := make([]string, words)
+	for i := range parts {
+		parts[i] = f.Word()
+	}
+	rv := strings.Join(parts, " ")
+	if rv == "" {
+		return rv
+	}
+	return strings.ToUpper(rv[:1]) + rv[1:] + "."
+}
+
+// Returns a random IPv4 address.
+func (f *Faker) IPv4() netip.Addr {
+	var b [4]byte
+	for i := range b {
+		b[i] = byte(f.UintN(256))
+	}
+	return netip.AddrFrom4(b)
+}
+
+// Returns a random IPv6 address.
+func (f *Faker) IPv6() netip.Addr {
+	var b [16]byte
+	for i := range b {
+		b[i] = byte(f.UintN(256))
+	}
+	return netip.AddrFrom16(b)
+}
+
+func seedFromName(name string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return h.Sum64()
+}
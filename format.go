@@ -0,0 +1,304 @@
+package sbtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type (
+	// Describes a single assertion failure. This is what is given to a
+	// [Formatter] to turn into the message that is reported to the test.
+	Failure struct {
+		Expected any
+		Got      any
+		// The message describing why the assertion failed.
+		Msg string
+		// The file and line of the assertion that failed.
+		File string
+		Line int
+		// The name of the assertion that failed, such as "Eq". This will be
+		// empty if [FormatError] was called directly from outside this
+		// package.
+		Assertion string
+		// The stack of the goroutine that the assertion failed on, excluding
+		// frames from this package and the testing package. This will be
+		// empty unless stack traces are enabled with [SetStackTraces].
+		Stack string
+		// The line of source code that contains the failed assertion with a
+		// caret under the assertion call. This will be empty unless source
+		// excerpts are enabled with [SetSourceExcerpts] or the source file
+		// could not be read.
+		Source string
+	}
+
+	// The JSON record that is written for each failure when JSON output is
+	// enabled with [SetJSONOutput].
+	jsonFailure struct {
+		Test         string `json:"test"`
+		File         string `json:"file"`
+		Line         int    `json:"line"`
+		Assertion    string `json:"assertion"`
+		Msg          string `json:"msg"`
+		Expected     string `json:"expected"`
+		ExpectedType string `json:"expectedType"`
+		Got          string `json:"got"`
+		GotType      string `json:"gotType"`
+	}
+
+	// Turns a failure into the message that is reported to the test. Set the
+	// formatter that is used by all assertions with [SetFormatter].
+	Formatter interface {
+		Format(f Failure) string
+	}
+
+	// An adapter that allows an ordinary function to be used as a
+	// [Formatter].
+	FormatterFunc func(f Failure) string
+
+	// The formatter that is used when no other formatter has been set. Refer
+	// to [FormatError] for the format that it produces.
+	DefaultFormatter struct{}
+)
+
+var (
+	formatterMu sync.RWMutex
+	formatter   Formatter = DefaultFormatter{}
+
+	jsonOutputMu sync.Mutex
+	jsonOutput   io.Writer
+
+	stackTraces    atomic.Bool
+	sourceExcerpts atomic.Bool
+)
+
+// Sets the formatter that is used by [FormatError], and therefore by all
+// assertions in this package, and returns the previously set formatter. If the
+// supplied formatter is nil the [DefaultFormatter] is used. The formatter is
+// package wide state, so tests that change it should restore it when they are
+// done, as shown below.
+//
+//	prev := SetFormatter(myFormatter)
+//	t.Cleanup(func() { SetFormatter(prev) })
+func SetFormatter(f Formatter) Formatter {
+	if f == nil {
+		f = DefaultFormatter{}
+	}
+	formatterMu.Lock()
+	defer formatterMu.Unlock()
+	prev := formatter
+	formatter = f
+	return prev
+}
+
+func activeFormatter() Formatter {
+	formatterMu.RLock()
+	defer formatterMu.RUnlock()
+	return formatter
+}
+
+// Sets the writer that a single line JSON record is written to for every
+// failure, in addition to the failure being reported to the test as normal.
+// This allows CI systems to parse and aggregate failures across a test suite.
+// Returns the previously set writer. Supplying a nil writer disables JSON
+// output, which is the default. Each record has the following fields:
+//
+//	{
+//		"test": "<test name>",
+//		"file": "<file>",
+//		"line": ####,
+//		"assertion": "<assertion name>",
+//		"msg": "<message>",
+//		"expected": "<value>",
+//		"expectedType": "<type>",
+//		"got": "<value>",
+//		"gotType": "<type>"
+//	}
+//
+// Failures that are recorded instead of reported, such as the failures of a
+// [RetryTest] attempt or of an assertion made with a [Group], are not written.
+// A record is only written for the failure that is reported to the test when
+// the recorded failures are, such as when every attempt fails or
+// [Group.Report] is called. Writes to the writer are serialized so it is safe
+// to use with parallel tests. Errors returned from the writer are ignored.
+func SetJSONOutput(w io.Writer) io.Writer {
+	jsonOutputMu.Lock()
+	defer jsonOutputMu.Unlock()
+	prev := jsonOutput
+	jsonOutput = w
+	return prev
+}
+
+func writeJSONFailure(t testing.TB, f Failure) {
+	if _, ok := t.(recordingTB); ok {
+		// The failure only reaches the test, and so needs a record, if it is
+		// later reported by whatever is recording it, such as a retry that
+		// never passes.
+		return
+	}
+	jsonOutputMu.Lock()
+	defer jsonOutputMu.Unlock()
+	if jsonOutput == nil {
+		return
+	}
+	data, err := json.Marshal(jsonFailure{
+		Test:         t.Name(),
+		File:         f.File,
+		Line:         f.Line,
+		Assertion:    f.Assertion,
+		Msg:          f.Msg,
+		Expected:     fmt.Sprintf("%v", f.Expected),
+		ExpectedType: fmt.Sprintf("%T", f.Expected),
+		Got:          fmt.Sprintf("%v", f.Got),
+		GotType:      fmt.Sprintf("%T", f.Got),
+	})
+	if err != nil {
+		return
+	}
+	jsonOutput.Write(append(data, '\n'))
+}
+
+// Sets whether a stack trace is included with every failure and returns the
+// previous setting. Stack traces are disabled by default. Frames from this
+// package and from the testing package are not included in the trace, so
+// failures that happen deep inside test helper functions can be traced back to
+// the test body. Like all package options this is package wide state.
+func SetStackTraces(enabled bool) bool {
+	return stackTraces.Swap(enabled)
+}
+
+// Returns the stack trace of the current goroutine with frames from this
+// package, the testing package, and the runtime removed. Each frame is
+// formatted as the function name followed by a tab indented file and line, the
+// same layout used by [runtime/debug.Stack].
+func failureStack() string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	var sb strings.Builder
+	for {
+		frame, more := frames.Next()
+		if !isPkgFrame(frame.Function) &&
+			!strings.HasPrefix(frame.Function, "testing.") &&
+			!strings.HasPrefix(frame.Function, "runtime.") {
+			if sb.Len() > 0 {
+				sb.WriteString("\n")
+			}
+			fmt.Fprintf(&sb, "%s\n\t%s:%d", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return sb.String()
+}
+
+// Sets whether an excerpt of the source code that contains the failed
+// assertion is included with every failure and returns the previous setting.
+// Source excerpts are disabled by default. The excerpt is read from the file
+// reported in the failure, so it is only available when the source is present
+// on the machine running the tests. Like all package options this is package
+// wide state.
+func SetSourceExcerpts(enabled bool) bool {
+	return sourceExcerpts.Swap(enabled)
+}
+
+// Returns the supplied line of the supplied file with a caret placed under the
+// call to the supplied assertion. If the assertion cannot be found in the line
+// the caret is placed under the first non-whitespace character. An empty string
+// is returned if the line cannot be read.
+func sourceExcerpt(file string, line int, assertion string) string {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return ""
+	}
+	lines := strings.Split(string(data), "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	src := strings.TrimRight(lines[line-1], " \t\r")
+
+	col := -1
+	if assertion != "" {
+		col = strings.Index(src, assertion+"(")
+		if col < 0 {
+			col = strings.Index(src, assertion+"[")
+		}
+	}
+	if col < 0 {
+		col = len(src) - len(strings.TrimLeft(src, " \t"))
+	}
+
+	// Copy any tabs so the caret lines up regardless of tab width
+	var caret strings.Builder
+	for _, iterChar := range src[:col] {
+		if iterChar == '\t' {
+			caret.WriteRune('\t')
+		} else {
+			caret.WriteRune(' ')
+		}
+	}
+	caret.WriteRune('^')
+
+	lineNum := strconv.Itoa(line)
+	return fmt.Sprintf(
+		"%s | %s\n%s | %s",
+		lineNum, src, strings.Repeat(" ", len(lineNum)), caret.String(),
+	)
+}
+
+// Returns the name of the outer most function in this package on the current
+// call stack, which is the assertion that the user called. Generic type
+// parameters and closure suffixes are removed from the name.
+func assertionName() string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	rv := ""
+	for {
+		frame, more := frames.Next()
+		if name, ok := pkgFuncName(frame.Function); ok {
+			rv = name
+		} else if rv != "" {
+			break
+		}
+		if !more {
+			break
+		}
+	}
+	if i := strings.IndexAny(rv, "[."); i >= 0 {
+		rv = rv[:i]
+	}
+	if rv == "FormatError" {
+		return ""
+	}
+	return rv
+}
+
+// Calls the underlying function.
+func (f FormatterFunc) Format(failure Failure) string {
+	return f(failure)
+}
+
+// Formats the failure with the packages default layout. If the failure has a
+// source excerpt or a stack trace they are appended to the end of the message.
+func (DefaultFormatter) Format(f Failure) string {
+	rv := fmt.Sprintf(
+		"Error | File %s Line %d | %s\nExpected: (%T) '%v'\nGot     : (%T) '%v'",
+		f.File, f.Line, f.Msg, f.Expected, f.Expected, f.Got, f.Got,
+	)
+	if f.Source != "" {
+		rv += "\nSource:\n" + f.Source
+	}
+	if f.Stack != "" {
+		rv += "\nStack:\n" + f.Stack
+	}
+	return rv
+}
@@ -0,0 +1,61 @@
+package sbtest
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// A test that records the failures reported to it instead of failing, so that
+// recorded failures can be reported through the same path as with a real test.
+type reportingTB struct {
+	testing.TB
+	msgs []string
+}
+
+func (r *reportingTB) Fatal(args ...any) {
+	r.msgs = append(r.msgs, fmt.Sprint(args...))
+	runtime.Goexit()
+}
+
+// Runs fn with a [reportingTB] and returns the failures reported to it.
+func reportedFailures(t testing.TB, fn func(t testing.TB)) []string {
+	r := &reportingTB{TB: t}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(r)
+	}()
+	<-done
+	return r.msgs
+}
+
+func TestJSONOutputSkipsRecordedFailures(t *testing.T) {
+	var buf bytes.Buffer
+	prev := SetJSONOutput(&buf)
+	defer SetJSONOutput(prev)
+
+	msgs := reportedFailures(t, func(t testing.TB) {
+		g := NewGroup(t)
+		Eq(g, 1, 2)
+		Eq(g, 3, 4)
+		g.Report()
+	})
+	Eq(t, 1, len(msgs))
+	records := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	Eq(t, 1, len(records))
+	True(t, strings.Contains(records[0], `"msg":"Assertions in the group failed.`))
+	True(t, strings.Contains(records[0], `"test":"`+t.Name()+`"`))
+
+	buf.Reset()
+	msgs = reportedFailures(t, func(t testing.TB) {
+		RetryTest(t, 3, 0, func(r *R) { Eq(r, 1, 2) })
+	})
+	Eq(t, 1, len(msgs))
+	records = strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	Eq(t, 1, len(records))
+	True(t, strings.Contains(records[0], `"msg":"Every attempt failed.`))
+	True(t, strings.Contains(records[0], `"got":"3 failed attempts"`))
+}
@@ -0,0 +1,183 @@
+package sbtest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Tests that a regular file exists at the supplied path.
+func FileExists(t testing.TB, path string) {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		f, line := callerLoc()
+		FormatError(
+			t, "file", errChain(err),
+			fmt.Sprintf("The file does not exist | Path: %s", path),
+			f, line,
+		)
+		return
+	}
+	if !info.Mode().IsRegular() {
+		f, line := callerLoc()
+		FormatError(
+			t, "file", info.Mode().String(),
+			fmt.Sprintf("The path exists but is not a file | Path: %s", path),
+			f, line,
+		)
+	}
+}
+
+// Tests that a directory exists at the supplied path.
+func DirExists(t testing.TB, path string) {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		f, line := callerLoc()
+		FormatError(
+			t, "dir", errChain(err),
+			fmt.Sprintf("The directory does not exist | Path: %s", path),
+			f, line,
+		)
+		return
+	}
+	if !info.IsDir() {
+		f, line := callerLoc()
+		FormatError(
+			t, "dir", info.Mode().String(),
+			fmt.Sprintf("The path exists but is not a directory | Path: %s", path),
+			f, line,
+		)
+	}
+}
+
+// Tests that nothing, neither a file nor a directory, exists at the supplied
+// path.
+func NoFileExists(t testing.TB, path string) {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err == nil {
+		f, line := callerLoc()
+		FormatError(
+			t, "nothing", info.Mode().String(),
+			fmt.Sprintf("The path exists when it was not expected to | Path: %s", path),
+			f, line,
+		)
+	}
+}
+
+// Tests that the contents of the file at the supplied path are equal to the
+// expected contents. A line based diff of the contents is shown on failure.
+func FileEq(t testing.TB, path string, expectedContents string) {
+	t.Helper()
+	f, line := callerLoc()
+	data, ok := readFile(t, path, f, line)
+	if ok && string(data) != expectedContents {
+		FormatError(
+			t, expectedContents, string(data),
+			fmt.Sprintf(
+				"The file contents did not match | Path: %s\nDiff:%s",
+				path, lineDiff(expectedContents, string(data)),
+			),
+			f, line,
+		)
+	}
+}
+
+// Tests that the contents of the file at the supplied path contain the
+// supplied substring.
+func FileContains(t testing.TB, path string, substr string) {
+	t.Helper()
+	f, line := callerLoc()
+	data, ok := readFile(t, path, f, line)
+	if ok && !strings.Contains(string(data), substr) {
+		FormatError(
+			t, substr, string(data),
+			fmt.Sprintf(
+				"The file did not contain the expected substring | Path: %s",
+				path,
+			),
+			f, line,
+		)
+	}
+}
+
+func readFile(
+	t testing.TB,
+	path string,
+	file string,
+	line int,
+) ([]byte, bool) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		FormatError(
+			t, nil, errChain(err),
+			fmt.Sprintf("The file could not be read | Path: %s", path),
+			file, line,
+		)
+		return nil, false
+	}
+	return data, true
+}
+
+// Creates a temporary directory that is populated with the supplied files and
+// returns the path to it. The keys of the files map are paths relative to the
+// temporary directory and the values are the contents of each file. Any
+// intermediate directories are created as needed. The directory is removed
+// when the test and all its subtests complete.
+func TempDirWith(t testing.TB, files map[string]string) string {
+	t.Helper()
+	f, line := callerLoc()
+	dir := t.TempDir()
+	for name, contents := range files {
+		if !filepath.IsLocal(name) {
+			FormatError(
+				t, "local path", name,
+				"The supplied file path was not a local path.",
+				f, line,
+			)
+			return dir
+		}
+		if !writeFile(t, filepath.Join(dir, name), contents, f, line) {
+			return dir
+		}
+	}
+	return dir
+}
+
+// Creates a temporary file with the supplied contents and returns the path to
+// it. The file is removed when the test and all its subtests complete.
+func TempFileWith(t testing.TB, contents string) string {
+	t.Helper()
+	f, line := callerLoc()
+	path := filepath.Join(t.TempDir(), "file")
+	writeFile(t, path, contents, f, line)
+	return path
+}
+
+func writeFile(
+	t testing.TB,
+	path string,
+	contents string,
+	file string,
+	line int,
+) bool {
+	t.Helper()
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err == nil {
+		err = os.WriteFile(path, []byte(contents), 0644)
+	}
+	if err != nil {
+		FormatError(
+			t, nil, errChain(err),
+			fmt.Sprintf("The file could not be written | Path: %s", path),
+			file, line,
+		)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,294 @@
+package sbtest
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+type (
+	// Adapts a fuzz target that takes a single typed value to [testing.F],
+	// which only supports a fixed set of primitive argument types. Values of
+	// T are encoded to and decoded from the []byte inputs that the fuzzing
+	// engine mutates, so T is usually a struct with one field per argument.
+	//
+	//	type parseArgs struct {
+	//		Input string
+	//		Strict bool
+	//	}
+	//
+	//	func FuzzParse(f *testing.F) {
+	//		a := sbtest.NewFuzzAdapter[parseArgs](f)
+	//		a.Add(parseArgs{Input: "a=1", Strict: true})
+	//		a.Fuzz(func(t testing.TB, v parseArgs) {
+	//			got, err := Parse(v.Input, v.Strict)
+	//			sbtest.Nil(t, err)
+	//			sbtest.Eq(t, v.Input, got.String())
+	//		})
+	//	}
+	//
+	// Booleans, numbers, strings, slices, arrays, maps, pointers, and the
+	// exported fields of structs are encoded. All other values are left as
+	// their zero value.
+	FuzzAdapter[T any] struct {
+		f     *testing.F
+		valid func(v T) bool
+	}
+)
+
+// Creates a new fuzz adapter for the supplied fuzz test.
+func NewFuzzAdapter[T any](f *testing.F) *FuzzAdapter[T] {
+	return &FuzzAdapter[T]{f: f}
+}
+
+// Adds the supplied values to the seed corpus of the fuzz test.
+func (a *FuzzAdapter[T]) Add(vals ...T) {
+	for _, iterVal := range vals {
+		a.f.Add(fuzzEncode(nil, reflect.ValueOf(&iterVal).Elem()))
+	}
+}
+
+// Sets a function that reports whether a decoded value is a valid input for
+// the fuzz target. Inputs that are not valid are skipped rather than run.
+// Returns the adapter so it can be chained with [NewFuzzAdapter].
+func (a *FuzzAdapter[T]) Valid(fn func(v T) bool) *FuzzAdapter[T] {
+	a.valid = fn
+	return a
+}
+
+// Runs the supplied fuzz target for every input of the fuzz test. Inputs that
+// cannot be decoded into a value of type T, or that are rejected by the
+// function set with [FuzzAdapter.Valid], are skipped. The target makes
+// assertions using the supplied [testing.TB], and any failure or panic fails
+// the input with the decoded value and every failure of the target in the
+// standard format of this package.
+func (a *FuzzAdapter[T]) Fuzz(fn func(t testing.TB, v T)) {
+	a.f.Helper()
+	f, line := callerLoc()
+	a.f.Fuzz(func(t *testing.T, data []byte) {
+		var v T
+		if _, err := fuzzDecode(data, reflect.ValueOf(&v).Elem()); err != nil {
+			t.Skipf("sbtest: fuzz input could not be decoded: %v", err)
+		}
+		if a.valid != nil && !a.valid(v) {
+			t.Skip("sbtest: fuzz input was not valid")
+		}
+		msgs := recordFailures(t, func(t testing.TB) { fn(t, v) })
+		if len(msgs) > 0 {
+			FormatError(
+				t, "the fuzz target to pass", v,
+				fmt.Sprintf(
+					"The fuzz target failed | Input: %+v\nFailures:%s",
+					v, fmtPropertyFailures(msgs),
+				),
+				f, line,
+			)
+		}
+	})
+}
+
+var errFuzzShortInput = errors.New("input ended early")
+
+// Appends the encoding of v to buf. The encoding is the inverse of
+// [fuzzDecode].
+func fuzzEncode(buf []byte, v reflect.Value) []byte {
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return append(buf, 1)
+		}
+		return append(buf, 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fuzzAppendUint(buf, uint64(v.Int()), int(v.Type().Size()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return fuzzAppendUint(buf, v.Uint(), int(v.Type().Size()))
+	case reflect.Float32:
+		return fuzzAppendUint(buf, uint64(math.Float32bits(float32(v.Float()))), 4)
+	case reflect.Float64:
+		return fuzzAppendUint(buf, math.Float64bits(v.Float()), 8)
+	case reflect.Complex64, reflect.Complex128:
+		size := int(v.Type().Size()) / 2
+		c := v.Complex()
+		if size == 4 {
+			buf = fuzzAppendUint(buf, uint64(math.Float32bits(float32(real(c)))), 4)
+			return fuzzAppendUint(buf, uint64(math.Float32bits(float32(imag(c)))), 4)
+		}
+		buf = fuzzAppendUint(buf, math.Float64bits(real(c)), 8)
+		return fuzzAppendUint(buf, math.Float64bits(imag(c)), 8)
+	case reflect.String:
+		buf = binary.AppendUvarint(buf, uint64(v.Len()))
+		return append(buf, v.String()...)
+	case reflect.Slice:
+		buf = binary.AppendUvarint(buf, uint64(v.Len()))
+		for i := range v.Len() {
+			buf = fuzzEncode(buf, v.Index(i))
+		}
+		return buf
+	case reflect.Array:
+		for i := range v.Len() {
+			buf = fuzzEncode(buf, v.Index(i))
+		}
+		return buf
+	case reflect.Map:
+		buf = binary.AppendUvarint(buf, uint64(v.Len()))
+		for iter := v.MapRange(); iter.Next(); {
+			buf = fuzzEncode(buf, iter.Key())
+			buf = fuzzEncode(buf, iter.Value())
+		}
+		return buf
+	case reflect.Pointer:
+		if v.IsNil() {
+			return append(buf, 0)
+		}
+		return fuzzEncode(append(buf, 1), v.Elem())
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if v.Type().Field(i).IsExported() {
+				buf = fuzzEncode(buf, v.Field(i))
+			}
+		}
+		return buf
+	}
+	return buf
+}
+
+// Decodes data into v, returning the remaining data. Any input that is long
+// enough decodes successfully so the fuzzing engine can freely mutate it.
+func fuzzDecode(data []byte, v reflect.Value) ([]byte, error) {
+	var err error
+	switch v.Kind() {
+	case reflect.Bool:
+		var b uint64
+		b, data, err = fuzzReadUint(data, 1)
+		v.SetBool(b&1 == 1)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var u uint64
+		size := int(v.Type().Size())
+		u, data, err = fuzzReadUint(data, size)
+		// Sign extend from the encoded size.
+		v.SetInt(int64(u<<(64-8*size)) >> (64 - 8*size))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		var u uint64
+		u, data, err = fuzzReadUint(data, int(v.Type().Size()))
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		size := int(v.Type().Size())
+		parts := 1
+		if v.Kind() == reflect.Complex64 || v.Kind() == reflect.Complex128 {
+			size, parts = size/2, 2
+		}
+		f := [2]float64{}
+		for i := range parts {
+			var u uint64
+			if u, data, err = fuzzReadUint(data, size); err != nil {
+				return data, err
+			}
+			f[i] = math.Float64frombits(u)
+			if size == 4 {
+				f[i] = float64(math.Float32frombits(uint32(u)))
+			}
+		}
+		if parts == 2 {
+			v.SetComplex(complex(f[0], f[1]))
+		} else {
+			v.SetFloat(f[0])
+		}
+	case reflect.String:
+		var n int
+		if n, data, err = fuzzReadLen(data); err != nil {
+			return data, err
+		}
+		v.SetString(string(data[:n]))
+		data = data[n:]
+	case reflect.Slice:
+		var n int
+		if n, data, err = fuzzReadLen(data); err != nil {
+			return data, err
+		}
+		v.Set(reflect.MakeSlice(v.Type(), n, n))
+		for i := range n {
+			if data, err = fuzzDecode(data, v.Index(i)); err != nil {
+				return data, err
+			}
+		}
+	case reflect.Array:
+		for i := range v.Len() {
+			if data, err = fuzzDecode(data, v.Index(i)); err != nil {
+				return data, err
+			}
+		}
+	case reflect.Map:
+		var n int
+		if n, data, err = fuzzReadLen(data); err != nil {
+			return data, err
+		}
+		v.Set(reflect.MakeMapWithSize(v.Type(), n))
+		for range n {
+			key := reflect.New(v.Type().Key()).Elem()
+			if data, err = fuzzDecode(data, key); err != nil {
+				return data, err
+			}
+			val := reflect.New(v.Type().Elem()).Elem()
+			if data, err = fuzzDecode(data, val); err != nil {
+				return data, err
+			}
+			v.SetMapIndex(key, val)
+		}
+	case reflect.Pointer:
+		var b uint64
+		if b, data, err = fuzzReadUint(data, 1); err != nil || b&1 == 0 {
+			return data, err
+		}
+		v.Set(reflect.New(v.Type().Elem()))
+		return fuzzDecode(data, v.Elem())
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+			if data, err = fuzzDecode(data, v.Field(i)); err != nil {
+				return data, err
+			}
+		}
+	}
+	return data, err
+}
+
+func fuzzAppendUint(buf []byte, u uint64, size int) []byte {
+	for i := range size {
+		buf = append(buf, byte(u>>(8*i)))
+	}
+	return buf
+}
+
+func fuzzReadUint(data []byte, size int) (uint64, []byte, error) {
+	if len(data) < size {
+		return 0, data, errFuzzShortInput
+	}
+	var rv uint64
+	for i := range size {
+		rv |= uint64(data[i]) << (8 * i)
+	}
+	return rv, data[size:], nil
+}
+
+// Reads a length, which can be at most the length of the remaining data so
+// that mutated inputs cannot cause huge allocations.
+func fuzzReadLen(data []byte) (int, []byte, error) {
+	n, read := binary.Uvarint(data)
+	if read <= 0 {
+		return 0, data, errFuzzShortInput
+	}
+	data = data[read:]
+	if n > uint64(len(data)) {
+		return 0, data, fmt.Errorf(
+			"length %d is longer than the remaining %d bytes", n, len(data),
+		)
+	}
+	return int(n), data, nil
+}
@@ -0,0 +1,94 @@
+package sbtest
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+type fuzzCodecArgs struct {
+	B      bool
+	I8     int8
+	I      int
+	U16    uint16
+	F32    float32
+	F64    float64
+	C64    complex64
+	C128   complex128
+	S      string
+	Bytes  []byte
+	Arr    [2]int32
+	M      map[string]int
+	P      *int
+	NilP   *int
+	Nested []fuzzCodecNested
+	hidden int
+}
+
+type fuzzCodecNested struct {
+	Name string
+	Tags []string
+}
+
+func TestFuzzCodecRoundTrip(t *testing.T) {
+	p := -7
+	v := fuzzCodecArgs{
+		B:      true,
+		I8:     math.MinInt8,
+		I:      math.MinInt64,
+		U16:    math.MaxUint16,
+		F32:    -1.5,
+		F64:    math.Inf(1),
+		C64:    complex(1, -2),
+		C128:   complex(-3, 4),
+		S:      "héllo",
+		Bytes:  []byte{0, 1, 255},
+		Arr:    [2]int32{-1, math.MaxInt32},
+		M:      map[string]int{"a": 1, "b": -2},
+		P:      &p,
+		Nested: []fuzzCodecNested{{Name: "x", Tags: []string{"t"}}, {Tags: []string{}}},
+		hidden: 3,
+	}
+	data := fuzzEncode(nil, reflect.ValueOf(v))
+
+	var got fuzzCodecArgs
+	rest, err := fuzzDecode(data, reflect.ValueOf(&got).Elem())
+	Nil(t, err)
+	Eq(t, 0, len(rest))
+	v.hidden = 0
+	True(t, reflect.DeepEqual(v, got))
+}
+
+func TestFuzzCodecNilIsEmpty(t *testing.T) {
+	// The encoding does not distinguish nil from empty slices and maps.
+	var got fuzzCodecArgs
+	_, err := fuzzDecode(
+		fuzzEncode(nil, reflect.ValueOf(fuzzCodecArgs{})),
+		reflect.ValueOf(&got).Elem(),
+	)
+	Nil(t, err)
+	True(t, got.Bytes != nil && len(got.Bytes) == 0)
+	True(t, got.M != nil && len(got.M) == 0)
+	Nil(t, got.P)
+}
+
+func TestFuzzDecodeShortInput(t *testing.T) {
+	data := fuzzEncode(nil, reflect.ValueOf(fuzzCodecArgs{S: "abc"}))
+	for i := range len(data) {
+		var got fuzzCodecArgs
+		_, err := fuzzDecode(data[:i], reflect.ValueOf(&got).Elem())
+		True(t, err != nil)
+	}
+}
+
+func TestFuzzDecodeBoundsLengths(t *testing.T) {
+	// A length far longer than the remaining input must be rejected rather
+	// than allocated.
+	data := []byte{0xff, 0xff, 0xff, 0xff, 0x0f, 1, 2}
+	var s []int64
+	_, err := fuzzDecode(data, reflect.ValueOf(&s).Elem())
+	True(t, err != nil)
+	var str string
+	_, err = fuzzDecode(data, reflect.ValueOf(&str).Elem())
+	True(t, err != nil)
+}
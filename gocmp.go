@@ -0,0 +1,32 @@
+package sbtest
+
+import (
+	"strings"
+	"testing"
+
+	gocmp "github.com/google/go-cmp/cmp"
+)
+
+// Tests that the supplied values are equal according to [gocmp.Equal] from
+// github.com/google/go-cmp, using the supplied options. This allows options
+// such as cmpopts.IgnoreFields or [gocmp.Comparer] to be used while reporting
+// failures in the same way as every other assertion in this package. The diff
+// produced by [gocmp.Diff] is shown on failure, where lines prefixed with '-'
+// are from expected and lines prefixed with '+' are from got.
+//
+// Like go-cmp itself this panics if the values contain unexported fields and
+// no option has been supplied that specifies how to handle them.
+func EqCmp(t testing.TB, expected any, got any, opts ...gocmp.Option) {
+	t.Helper()
+	diff := gocmp.Diff(expected, got, opts...)
+	if diff == "" {
+		return
+	}
+	f, line := callerLoc()
+	FormatError(
+		t, expected, got,
+		"The supplied values were not equal but were expected to be.\nDiff:\n"+
+			strings.TrimRight(diff, "\n"),
+		f, line,
+	)
+}
@@ -0,0 +1,61 @@
+package sbtest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Setting this environment variable to any non-empty value causes all golden
+// file assertions to write the value they were given to the golden file
+// instead of comparing against it.
+const GoldenUpdateEnvVar = "SBTEST_UPDATE_GOLDEN"
+
+// Tests that the supplied value is equal to the contents of the golden file at
+// the supplied path. A line based diff is shown on failure. Golden files are
+// conventionally placed under the testdata directory of a package.
+//
+// When the environment variable named by [GoldenUpdateEnvVar] is set the
+// golden file is created, or overwritten, with the supplied value and the
+// assertion passes.
+//
+//	SBTEST_UPDATE_GOLDEN=1 go test ./...
+func EqGolden(t testing.TB, path string, got string) {
+	t.Helper()
+	f, line := callerLoc()
+	eqGolden(t, path, got, f, line)
+}
+
+func eqGolden(t testing.TB, path string, got string, file string, line int) {
+	t.Helper()
+	if os.Getenv(GoldenUpdateEnvVar) != "" {
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err == nil {
+			err = os.WriteFile(path, []byte(got), 0644)
+		}
+		if err != nil {
+			FormatError(
+				t, nil, errChain(err),
+				fmt.Sprintf("The golden file could not be updated | Path: %s", path),
+				file, line,
+			)
+		}
+		return
+	}
+
+	data, ok := readFile(t, path, file, line)
+	if !ok {
+		return
+	}
+	if string(data) != got {
+		FormatError(
+			t, string(data), got,
+			fmt.Sprintf(
+				"The value did not match the golden file | Path: %s\nDiff:%s",
+				path, lineDiff(string(data), got),
+			),
+			file, line,
+		)
+	}
+}
@@ -0,0 +1,156 @@
+package sbtest
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type (
+	// Groups assertions so that their failures are recorded instead of
+	// stopping the test, providing soft assertions. Group implements
+	// [testing.TB] so it can be passed to any assertion in this package. Once
+	// all the assertions have been made Report should be called to emit all
+	// of the recorded failures as a single, numbered failure.
+	//
+	// Because failures do not stop the test, assertions that fail will return
+	// and the code following them will continue to run. Code that depends on
+	// an assertion passing, such as indexing into a slice after checking its
+	// length, should check [Group.Failed] first.
+	Group struct {
+		testing.TB
+		rec *failureRecorder
+
+		mu         sync.Mutex
+		invariants bool
+		violations map[string]struct{}
+	}
+)
+
+// Creates a new group of assertions that will report to the supplied test.
+func NewGroup(t testing.TB) *Group {
+	return &Group{TB: t, rec: &failureRecorder{}}
+}
+
+// Makes the group check the invariants registered with [RegisterInvariant]
+// before every assertion made with the group and when Report is called, so a
+// violation is caught by the first assertion after the code that caused it.
+// Each violation is recorded as a failure of the group once, at the location
+// of the assertion that found it. Returns the group so it can be chained with
+// [NewGroup].
+//
+//	g := sbtest.NewGroup(t).CheckInvariants()
+func (g *Group) CheckInvariants() *Group {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.invariants = true
+	return g
+}
+
+// Implements the [testing.TB] interface. If the group checks invariants and
+// the caller is an assertion from this package then the invariants are
+// checked first.
+func (g *Group) Helper() {
+	g.TB.Helper()
+	pcs := make([]uintptr, 2)
+	if runtime.Callers(2, pcs) < 2 {
+		return
+	}
+	frames := runtime.CallersFrames(pcs)
+	assertion, _ := frames.Next()
+	caller, _ := frames.Next()
+	if isPkgFrame(assertion.Function) && !isPkgFrame(caller.Function) {
+		g.checkInvariants()
+	}
+}
+
+// Records every violated invariant that has not already been recorded as a
+// failure of the group.
+func (g *Group) checkInvariants() {
+	g.mu.Lock()
+	enabled := g.invariants
+	g.mu.Unlock()
+	if !enabled {
+		return
+	}
+	f, line := callerLoc()
+	violated := append(
+		invariantsFor(g.TB).check(), invariantsFor(g).check()...,
+	)
+	fresh := []violation{}
+	g.mu.Lock()
+	if g.violations == nil {
+		g.violations = map[string]struct{}{}
+	}
+	for _, iterViolation := range violated {
+		key := fmt.Sprintf(
+			"%s:%d:%s",
+			iterViolation.file, iterViolation.line, iterViolation.err,
+		)
+		if _, ok := g.violations[key]; !ok {
+			g.violations[key] = struct{}{}
+			fresh = append(fresh, iterViolation)
+		}
+	}
+	g.mu.Unlock()
+	if len(fresh) > 0 {
+		FormatError(
+			g, nil, fmt.Sprintf("%d violated invariants", len(fresh)),
+			"Invariants were violated."+fmtViolations(fresh),
+			f, line,
+		)
+	}
+}
+
+// Records the failure and continues the test.
+func (g *Group) Fatal(args ...any) { g.rec.Error(args...) }
+
+// Records the failure and continues the test.
+func (g *Group) Fatalf(format string, args ...any) { g.rec.Errorf(format, args...) }
+
+// Marks the group as failed and continues the test.
+func (g *Group) FailNow() { g.rec.Fail() }
+
+// Records the failure and continues the test.
+func (g *Group) Error(args ...any) { g.rec.Error(args...) }
+
+// Records the failure and continues the test.
+func (g *Group) Errorf(format string, args ...any) { g.rec.Errorf(format, args...) }
+
+// Marks the group as failed and continues the test.
+func (g *Group) Fail() { g.rec.Fail() }
+
+// Returns true if any assertion in the group has failed.
+func (g *Group) Failed() bool { return g.rec.Failed() }
+
+func (g *Group) recorder() *failureRecorder { return g.rec }
+
+func (g *Group) wrapped() testing.TB { return g.TB }
+
+// Emits all the failures recorded by the group as a single failure to the
+// underlying test and stops the test. Each failure is numbered in the order it
+// was recorded. If there were no failures then nothing is reported. If the
+// group checks invariants they are checked before reporting.
+func (g *Group) Report() {
+	g.TB.Helper()
+	g.checkInvariants()
+	if !g.rec.Failed() {
+		return
+	}
+
+	msgs := g.rec.Msgs()
+	var sb strings.Builder
+	for i, iterMsg := range msgs {
+		fmt.Fprintf(
+			&sb, "\n%d) %s", i+1, strings.ReplaceAll(iterMsg, "\n", "\n   "),
+		)
+	}
+	f, line := callerLoc()
+	FormatError(
+		g.TB, 0, len(msgs),
+		fmt.Sprintf("Assertions in the group failed.%s", sb.String()),
+		f, line,
+	)
+}
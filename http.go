@@ -0,0 +1,399 @@
+package sbtest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type (
+	// An [http.RoundTripper] that responds to requests with registered stubs
+	// instead of sending them over the network. Every request it receives is
+	// recorded so that assertions can be made about the outbound requests of
+	// the code under test. Requests that do not match any stub are answered
+	// with an error and recorded as unexpected. All methods are safe for
+	// concurrent use.
+	MockTransport struct {
+		mu       sync.Mutex
+		stubs    []httpStub
+		requests []RecordedRequest
+	}
+
+	// A request that was received by a [MockTransport].
+	RecordedRequest struct {
+		Method string
+		URL    string
+		Header http.Header
+		Body   []byte
+		// True if the request did not match any of the registered stubs.
+		Unexpected bool
+	}
+
+	httpStub struct {
+		method string
+		re     *regexp.Regexp
+		fn     func(req *http.Request) (*http.Response, error)
+	}
+)
+
+// Creates a new mock transport with no stubs.
+func NewMockTransport() *MockTransport {
+	return &MockTransport{}
+}
+
+// Returns an [http.Client] that sends all of its requests through the mock
+// transport.
+func (m *MockTransport) Client() *http.Client {
+	return &http.Client{Transport: m}
+}
+
+// Registers a stub that responds with the supplied status code and body to
+// requests with the supplied method whose full URL matches the supplied regex.
+// An empty method matches all methods. Stubs are matched in the order they
+// were registered.
+func (m *MockTransport) Stub(
+	method string,
+	urlPattern string,
+	status int,
+	body string,
+) {
+	m.StubFunc(
+		method, urlPattern,
+		func(req *http.Request) (*http.Response, error) {
+			return NewResponse(req, status, body), nil
+		},
+	)
+}
+
+// Registers a stub that calls the supplied function to respond to requests
+// with the supplied method whose full URL matches the supplied regex. Refer to
+// [MockTransport.Stub] for the matching rules.
+func (m *MockTransport) StubFunc(
+	method string,
+	urlPattern string,
+	fn func(req *http.Request) (*http.Response, error),
+) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stubs = append(m.stubs, httpStub{
+		method: method,
+		re:     regexp.MustCompile(urlPattern),
+		fn:     fn,
+	})
+}
+
+// Implements the [http.RoundTripper] interface. The stub is called with a copy
+// of the request whose body can still be read, the supplied request is not
+// modified.
+func (m *MockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	stubReq, body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	rec := RecordedRequest{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Header: req.Header.Clone(),
+		Body:   body,
+	}
+
+	m.mu.Lock()
+	var stub *httpStub
+	for i := range m.stubs {
+		if m.stubs[i].matches(rec.Method, rec.URL) {
+			stub = &m.stubs[i]
+			break
+		}
+	}
+	rec.Unexpected = (stub == nil)
+	m.requests = append(m.requests, rec)
+	m.mu.Unlock()
+
+	if stub == nil {
+		return nil, fmt.Errorf(
+			"sbtest: no stub registered for request: %s %s",
+			rec.Method, rec.URL,
+		)
+	}
+	resp, err := stub.fn(stubReq)
+	if resp != nil && resp.Request == stubReq {
+		resp.Request = req
+	}
+	return resp, err
+}
+
+// Reads and closes the body of the supplied request, as a round tripper must,
+// and returns the body along with a copy of the request whose body can be read
+// again. The supplied request is not modified, as required by
+// [http.RoundTripper].
+func readRequestBody(req *http.Request) (*http.Request, []byte, error) {
+	rv := req.Clone(req.Context())
+	if req.Body == nil {
+		return rv, nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+	rv.Body = io.NopCloser(bytes.NewReader(body))
+	rv.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return rv, body, nil
+}
+
+// Returns a copy of all requests that have been received so far, in the order
+// they were received.
+func (m *MockTransport) Requests() []RecordedRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]RecordedRequest{}, m.requests...)
+}
+
+// Tests that the transport received exactly n requests with the supplied
+// method whose full URL matches the supplied regex. An empty method matches
+// all methods. All received requests are listed on failure.
+func (m *MockTransport) AssertRequestedTimes(
+	t testing.TB,
+	method string,
+	urlPattern string,
+	n int,
+) {
+	t.Helper()
+	stub := httpStub{method: method, re: regexp.MustCompile(urlPattern)}
+	requests := m.Requests()
+	cnt := 0
+	for _, iterReq := range requests {
+		if stub.matches(iterReq.Method, iterReq.URL) {
+			cnt++
+		}
+	}
+	if cnt != n {
+		f, line := callerLoc()
+		FormatError(
+			t, n, cnt,
+			fmt.Sprintf(
+				"The transport did not receive the expected number of requests | Method: %s | Pattern: %s\nRequests:%s",
+				method, urlPattern, fmtRequests(requests),
+			),
+			f, line,
+		)
+	}
+}
+
+// Tests that every request received by the transport matched a registered
+// stub. All unexpected requests are listed on failure.
+func (m *MockTransport) AssertNoUnexpectedRequests(t testing.TB) {
+	t.Helper()
+	unexpected := []RecordedRequest{}
+	for _, iterReq := range m.Requests() {
+		if iterReq.Unexpected {
+			unexpected = append(unexpected, iterReq)
+		}
+	}
+	if len(unexpected) > 0 {
+		f, line := callerLoc()
+		FormatError(
+			t, 0, len(unexpected),
+			fmt.Sprintf(
+				"The transport received requests that did not match any stub\nRequests:%s",
+				fmtRequests(unexpected),
+			),
+			f, line,
+		)
+	}
+}
+
+// Creates a response to the supplied request with the supplied status code and
+// body. This is useful for writing stub functions for a [MockTransport].
+func NewResponse(req *http.Request, status int, body string) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// Tests that the supplied response contains all of the headers in want. Header
+// keys are compared case-insensitively and every value of a key in want must be
+// present in the response, in any order. Headers in the response that are not
+// in want are ignored, so headers the server adds on its own such as Date do
+// not need to be listed. Every missing header value is listed on failure.
+func HeadersContain(t testing.TB, resp *http.Response, want http.Header) {
+	t.Helper()
+	f, line := callerLoc()
+	if resp == nil {
+		FormatError(
+			t, want, nil,
+			"The supplied response was nil.",
+			f, line,
+		)
+		return
+	}
+	keys := make([]string, 0, len(want))
+	for k := range want {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		gotVals := slices.Clone(resp.Header.Values(k))
+		for _, iterVal := range want[k] {
+			if i := slices.Index(gotVals, iterVal); i >= 0 {
+				gotVals = slices.Delete(gotVals, i, i+1)
+				continue
+			}
+			fmt.Fprintf(
+				&sb, "\n  Key: %s | Missing: %q | Got: %q",
+				http.CanonicalHeaderKey(k), iterVal, resp.Header.Values(k),
+			)
+		}
+	}
+	if sb.Len() > 0 {
+		FormatError(
+			t, want, resp.Header,
+			"The response did not contain the expected headers.\nDifferences:"+
+				sb.String(),
+			f, line,
+		)
+	}
+}
+
+// Tests that the supplied response sets the cookie with the supplied name to
+// the expected value. If the response sets the cookie more than once the last
+// Set-Cookie header is used.
+func CookieEq(t testing.TB, resp *http.Response, name string, expectedValue string) {
+	t.Helper()
+	f, line := callerLoc()
+	cookie, ok := findCookie(t, resp, name, f, line)
+	if !ok {
+		return
+	}
+	if cookie.Value != expectedValue {
+		FormatError(
+			t, expectedValue, cookie.Value,
+			fmt.Sprintf("The cookie did not have the expected value | Name: %s", name),
+			f, line,
+		)
+	}
+}
+
+// Tests that the supplied response sets the cookie with the supplied name and
+// that the cookie has the same Value, Path, Domain, MaxAge, Secure, HttpOnly,
+// and SameSite attributes as want. The Expires attribute must be within +/-
+// expiresEps of want.Expires, and a zero want.Expires means the cookie must not
+// have an Expires attribute. If the response sets the cookie more than once the
+// last Set-Cookie header is used. Every attribute that differs is listed on
+// failure.
+func CookieAttrs(
+	t testing.TB,
+	resp *http.Response,
+	name string,
+	want http.Cookie,
+	expiresEps time.Duration,
+) {
+	t.Helper()
+	f, line := callerLoc()
+	cookie, ok := findCookie(t, resp, name, f, line)
+	if !ok {
+		return
+	}
+	want.Name = name
+
+	var sb strings.Builder
+	cookieAttrDiff(&sb, "Value", want.Value, cookie.Value)
+	cookieAttrDiff(&sb, "Path", want.Path, cookie.Path)
+	cookieAttrDiff(&sb, "Domain", want.Domain, cookie.Domain)
+	cookieAttrDiff(&sb, "MaxAge", want.MaxAge, cookie.MaxAge)
+	cookieAttrDiff(&sb, "Secure", want.Secure, cookie.Secure)
+	cookieAttrDiff(&sb, "HttpOnly", want.HttpOnly, cookie.HttpOnly)
+	cookieAttrDiff(&sb, "SameSite", want.SameSite, cookie.SameSite)
+	if want.Expires.IsZero() != cookie.Expires.IsZero() ||
+		want.Expires.Sub(cookie.Expires).Abs() > expiresEps {
+		fmt.Fprintf(
+			&sb, "\n  Expires | Expected: %v +/- %v | Got: %v",
+			want.Expires, expiresEps, cookie.Expires,
+		)
+	}
+	if sb.Len() > 0 {
+		FormatError(
+			t, want.String(), cookie.String(),
+			fmt.Sprintf(
+				"The cookie did not have the expected attributes | Name: %s\nDifferences:%s",
+				name, sb.String(),
+			),
+			f, line,
+		)
+	}
+}
+
+func findCookie(
+	t testing.TB,
+	resp *http.Response,
+	name string,
+	f string,
+	line int,
+) (*http.Cookie, bool) {
+	t.Helper()
+	if resp == nil {
+		FormatError(
+			t, name, nil,
+			"The supplied response was nil.",
+			f, line,
+		)
+		return nil, false
+	}
+	var rv *http.Cookie
+	names := []string{}
+	for _, iterCookie := range resp.Cookies() {
+		if iterCookie.Name == name {
+			rv = iterCookie
+		}
+		names = append(names, iterCookie.Name)
+	}
+	if rv == nil {
+		FormatError(
+			t, name, names,
+			"The response did not set the expected cookie.",
+			f, line,
+		)
+		return nil, false
+	}
+	return rv, true
+}
+
+func cookieAttrDiff[T comparable](sb *strings.Builder, name string, expected T, got T) {
+	if expected != got {
+		fmt.Fprintf(sb, "\n  %s | Expected: %v | Got: %v", name, expected, got)
+	}
+}
+
+func (s *httpStub) matches(method string, url string) bool {
+	return (s.method == "" || s.method == method) && s.re.MatchString(url)
+}
+
+func fmtRequests(requests []RecordedRequest) string {
+	if len(requests) == 0 {
+		return " <no requests>"
+	}
+	var sb strings.Builder
+	for i, iterReq := range requests {
+		fmt.Fprintf(&sb, "\n  %d: %s %s", i, iterReq.Method, iterReq.URL)
+	}
+	return sb.String()
+}
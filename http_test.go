@@ -0,0 +1,48 @@
+package sbtest
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMockTransportDoesNotModifyRequest(t *testing.T) {
+	m := NewMockTransport()
+	var stubBody string
+	m.StubFunc(
+		http.MethodPost, `/items$`,
+		func(req *http.Request) (*http.Response, error) {
+			b, err := io.ReadAll(req.Body)
+			stubBody = string(b)
+			req.Header.Set("X-Stub", "1")
+			return NewResponse(req, http.StatusCreated, "ok"), err
+		},
+	)
+
+	req, err := http.NewRequest(
+		http.MethodPost, "http://example.com/items", strings.NewReader("a=1"),
+	)
+	Nil(t, err)
+	origBody := req.Body
+	resp, err := m.RoundTrip(req)
+	Nil(t, err)
+	Eq(t, http.StatusCreated, resp.StatusCode)
+	True(t, resp.Request == req)
+	True(t, req.Body == origBody)
+	Eq(t, "", req.Header.Get("X-Stub"))
+	Eq(t, "a=1", stubBody)
+	Eq(t, "a=1", string(m.Requests()[0].Body))
+}
+
+func TestMockTransportUnexpectedRequest(t *testing.T) {
+	m := NewMockTransport()
+	_, err := m.Client().Get("http://example.com/missing")
+	True(t, err != nil)
+	msgs := recordFailures(t, func(t testing.TB) { m.AssertNoUnexpectedRequests(t) })
+	Eq(t, 1, len(msgs))
+	True(t, strings.Contains(
+		msgs[0], "did not match any stub\nRequests:",
+	))
+	True(t, strings.Contains(msgs[0], "http://example.com/missing"))
+}
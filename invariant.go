@@ -0,0 +1,119 @@
+package sbtest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type (
+	// The invariants registered with a single test.
+	invariantSet struct {
+		mu   sync.Mutex
+		invs []invariant
+	}
+
+	// A single invariant and the location it was registered at.
+	invariant struct {
+		fn   func() error
+		file string
+		line int
+	}
+
+	// An invariant that returned an error.
+	violation struct {
+		invariant
+		err error
+	}
+)
+
+// The invariants registered with each test by [RegisterInvariant].
+var invariants sync.Map
+
+// Registers an invariant with the supplied test. The invariant is checked when
+// the test and all its subtests complete, and the test fails listing every
+// registered invariant that returned an error. This is intended for data
+// structure tests where structural properties, such as a tree staying
+// balanced, must hold no matter which operations were performed.
+//
+//	sbtest.RegisterInvariant(t, tree.Validate)
+//
+// The invariants can also be checked at any point with [CheckInvariants], or
+// before every assertion made with a [Group] by calling
+// [Group.CheckInvariants].
+func RegisterInvariant(t testing.TB, fn func() error) {
+	t.Helper()
+	f, line := callerLoc()
+	set, loaded := invariants.LoadOrStore(t, &invariantSet{})
+	s := set.(*invariantSet)
+	s.mu.Lock()
+	s.invs = append(s.invs, invariant{fn: fn, file: f, line: line})
+	s.mu.Unlock()
+	if loaded {
+		return
+	}
+	t.Cleanup(func() {
+		t.Helper()
+		invariants.Delete(t)
+		if violated := s.check(); len(violated) > 0 {
+			FormatError(
+				t, nil, fmt.Sprintf("%d violated invariants", len(violated)),
+				"Invariants were violated at the end of the test."+
+					fmtViolations(violated),
+				violated[0].file, violated[0].line,
+			)
+		}
+	})
+}
+
+// Tests that every invariant registered with the supplied test by
+// [RegisterInvariant] currently holds. The test fails listing every invariant
+// that returned an error.
+func CheckInvariants(t testing.TB) {
+	t.Helper()
+	f, line := callerLoc()
+	if violated := invariantsFor(t).check(); len(violated) > 0 {
+		FormatError(
+			t, nil, fmt.Sprintf("%d violated invariants", len(violated)),
+			"Invariants were violated."+fmtViolations(violated),
+			f, line,
+		)
+	}
+}
+
+// Returns the invariants registered with the supplied test, which is empty if
+// none were registered.
+func invariantsFor(t testing.TB) *invariantSet {
+	if set, ok := invariants.Load(t); ok {
+		return set.(*invariantSet)
+	}
+	return &invariantSet{}
+}
+
+// Runs every invariant in the set and returns the ones that were violated,
+// along with their errors.
+func (s *invariantSet) check() []violation {
+	s.mu.Lock()
+	invs := append([]invariant{}, s.invs...)
+	s.mu.Unlock()
+	rv := []violation{}
+	for _, iterInv := range invs {
+		if err := iterInv.fn(); err != nil {
+			rv = append(rv, violation{invariant: iterInv, err: err})
+		}
+	}
+	return rv
+}
+
+func fmtViolations(violated []violation) string {
+	var sb strings.Builder
+	for _, iterViolation := range violated {
+		fmt.Fprintf(
+			&sb, "\n  Registered: File %s Line %d | Error: %s",
+			iterViolation.file, iterViolation.line,
+			strings.ReplaceAll(iterViolation.err.Error(), "\n", "\n    "),
+		)
+	}
+	return sb.String()
+}
@@ -0,0 +1,99 @@
+package sbtest
+
+import (
+	"fmt"
+	"iter"
+	"testing"
+)
+
+// Tests that the supplied sequence yields exactly the values in expected, in
+// the same order. For equality rules refer to the language reference:
+// https://go.dev/ref/spec#Comparison_operators
+//
+// At most one more value than expected is read from the sequence, so a
+// sequence that accidentally never ends fails the assertion instead of
+// hanging the test.
+func SeqMatch[T comparable](t testing.TB, expected []T, seq iter.Seq[T]) {
+	t.Helper()
+	f, line := callerLoc()
+	got := []T{}
+	for v := range seq {
+		got = append(got, v)
+		if len(got) > len(expected) {
+			break
+		}
+	}
+	if len(got) > len(expected) {
+		FormatError(
+			t, len(expected), fmt.Sprintf("at least %d", len(got)),
+			"The sequence yielded more values than expected.\nDiff:"+sliceDiff(
+				expected, got, func(l T, r T) bool { return l == r },
+			),
+			f, line,
+		)
+		return
+	}
+	slicesMatch(t, expected, got, f, line)
+}
+
+// Tests that the supplied sequence yields exactly the key value pairs in
+// expected, in any order, and yields each key only once. For equality rules
+// refer to the language reference:
+// https://go.dev/ref/spec#Comparison_operators
+//
+// At most one more pair than expected is read from the sequence, so a
+// sequence that accidentally never ends fails the assertion instead of
+// hanging the test.
+func Seq2MatchMap[K comparable, V comparable](
+	t testing.TB,
+	expected map[K]V,
+	seq iter.Seq2[K, V],
+) {
+	t.Helper()
+	f, line := callerLoc()
+	got := map[K]V{}
+	n := 0
+	for k, v := range seq {
+		n++
+		if n > len(expected) {
+			FormatError(
+				t, len(expected), fmt.Sprintf("at least %d", n),
+				fmt.Sprintf(
+					"The sequence yielded more pairs than expected | Key: %v | Value: %v",
+					k, v,
+				),
+				f, line,
+			)
+			return
+		}
+		if prev, ok := got[k]; ok {
+			FormatError(
+				t, prev, v,
+				fmt.Sprintf("The sequence yielded a key more than once | Key: %v", k),
+				f, line,
+			)
+			return
+		}
+		got[k] = v
+	}
+
+	for k, v := range expected {
+		gotV, ok := got[k]
+		if !ok {
+			FormatError(
+				t, v, nil,
+				fmt.Sprintf("The sequence did not yield a key | Key: %v", k),
+				f, line,
+			)
+			return
+		}
+		if gotV != v {
+			FormatError(
+				t, v, gotV,
+				fmt.Sprintf("The sequence yielded the wrong value | Key: %v", k),
+				f, line,
+			)
+			return
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package sbtest
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"testing"
+
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+type (
+	// The decoded parts of a JWT in its compact serialization.
+	jwtParts struct {
+		header       map[string]any
+		claims       map[string]any
+		signingInput string
+		signature    []byte
+	}
+)
+
+// Tests that the claims in the payload of the supplied JWT contain all of the
+// claims in want. Claims in the token that are not in want are ignored. The
+// values in want are compared against the claims after both have been
+// converted to their JSON representation, so an int in want matches a numeric
+// claim and a struct in want matches an object claim with the same fields.
+// Every claim that differs is listed on failure.
+//
+// The signature of the token is not verified, use [JWTValidSignature] for
+// that.
+func JWTClaimsEq(t testing.TB, token string, want map[string]any) {
+	t.Helper()
+	f, line := callerLoc()
+	parts, err := parseJWT(token)
+	if err != nil {
+		FormatError(
+			t, nil, errChain(err),
+			"The supplied token could not be parsed as a JWT.",
+			f, line,
+		)
+		return
+	}
+
+	keys := make([]string, 0, len(want))
+	for k := range want {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		wantVal, err := jsonNormalize(want[k])
+		if err != nil {
+			FormatError(
+				t, nil, errChain(err),
+				fmt.Sprintf("The expected claim could not be encoded as JSON | Claim: %s", k),
+				f, line,
+			)
+			return
+		}
+		gotVal, ok := parts.claims[k]
+		if !ok {
+			fmt.Fprintf(&sb, "\n  Claim: %s | Missing | Expected: %v", k, wantVal)
+		} else if !deepEqual(wantVal, gotVal) {
+			fmt.Fprintf(&sb, "\n  Claim: %s | Expected: %v | Got: %v", k, wantVal, gotVal)
+		}
+	}
+	if sb.Len() > 0 {
+		FormatError(
+			t, want, parts.claims,
+			"The token did not contain the expected claims.\nDifferences:"+sb.String(),
+			f, line,
+		)
+	}
+}
+
+// Tests that the signature of the supplied JWT is valid for the supplied key.
+// The algorithm is taken from the alg header of the token and the type of key
+// must match it:
+//   - HS256, HS384, HS512: []byte
+//   - RS256, RS384, RS512, PS256, PS384, PS512: *rsa.PublicKey
+//   - ES256, ES384, ES512: *ecdsa.PublicKey
+//   - EdDSA: ed25519.PublicKey
+//
+// Tokens with the none algorithm always fail. The claims of the token, such as
+// exp, are not validated.
+func JWTValidSignature(t testing.TB, token string, key any) {
+	t.Helper()
+	f, line := callerLoc()
+	parts, err := parseJWT(token)
+	if err != nil {
+		FormatError(
+			t, nil, errChain(err),
+			"The supplied token could not be parsed as a JWT.",
+			f, line,
+		)
+		return
+	}
+	alg, _ := parts.header["alg"].(string)
+	if err := verifyJWT(alg, parts.signingInput, parts.signature, key); err != nil {
+		FormatError(
+			t, nil, errChain(err),
+			fmt.Sprintf("The token signature was not valid | Alg: %s", alg),
+			f, line,
+		)
+	}
+}
+
+func parseJWT(token string) (jwtParts, error) {
+	segments := strings.Split(token, ".")
+	if len(segments) != 3 {
+		return jwtParts{}, fmt.Errorf(
+			"expected 3 dot separated segments, got %d", len(segments),
+		)
+	}
+	rv := jwtParts{signingInput: segments[0] + "." + segments[1]}
+	if err := decodeJWTSegment(segments[0], &rv.header); err != nil {
+		return jwtParts{}, fmt.Errorf("invalid header: %w", err)
+	}
+	if err := decodeJWTSegment(segments[1], &rv.claims); err != nil {
+		return jwtParts{}, fmt.Errorf("invalid claims: %w", err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(segments[2])
+	if err != nil {
+		return jwtParts{}, fmt.Errorf("invalid signature: %w", err)
+	}
+	rv.signature = sig
+	return rv, nil
+}
+
+func decodeJWTSegment(segment string, into *map[string]any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, into)
+}
+
+// Returns the value that results from encoding v as JSON and decoding it into
+// an any, which is how the claims of a token are represented.
+func jsonNormalize(v any) (any, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var rv any
+	err = json.Unmarshal(raw, &rv)
+	return rv, err
+}
+
+func verifyJWT(alg string, signingInput string, sig []byte, key any) error {
+	var hash crypto.Hash
+	switch alg {
+	case "HS256", "RS256", "PS256", "ES256":
+		hash = crypto.SHA256
+	case "HS384", "RS384", "PS384", "ES384":
+		hash = crypto.SHA384
+	case "HS512", "RS512", "PS512", "ES512":
+		hash = crypto.SHA512
+	case "EdDSA":
+		k, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return jwtKeyTypeErr(alg, key)
+		}
+		if !ed25519.Verify(k, []byte(signingInput), sig) {
+			return errors.New("signature verification failed")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+
+	h := hash.New()
+	h.Write([]byte(signingInput))
+	digest := h.Sum(nil)
+	switch alg[:2] {
+	case "HS":
+		k, ok := key.([]byte)
+		if !ok {
+			return jwtKeyTypeErr(alg, key)
+		}
+		mac := hmac.New(hash.New, k)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(mac.Sum(nil), sig) {
+			return errors.New("signature verification failed")
+		}
+		return nil
+	case "RS":
+		k, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return jwtKeyTypeErr(alg, key)
+		}
+		return rsa.VerifyPKCS1v15(k, hash, digest, sig)
+	case "PS":
+		k, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return jwtKeyTypeErr(alg, key)
+		}
+		return rsa.VerifyPSS(k, hash, digest, sig, nil)
+	default:
+		k, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return jwtKeyTypeErr(alg, key)
+		}
+		var curve elliptic.Curve
+		var size int
+		switch alg {
+		case "ES256":
+			curve, size = elliptic.P256(), 64
+		case "ES384":
+			curve, size = elliptic.P384(), 96
+		default:
+			curve, size = elliptic.P521(), 132
+		}
+		if k.Curve != curve {
+			return fmt.Errorf(
+				"algorithm %s requires a key on curve %s, got %s",
+				alg, curve.Params().Name, k.Curve.Params().Name,
+			)
+		}
+		if len(sig) != size {
+			return fmt.Errorf(
+				"invalid signature length %d, algorithm %s requires %d",
+				len(sig), alg, size,
+			)
+		}
+		r := new(big.Int).SetBytes(sig[:len(sig)/2])
+		s := new(big.Int).SetBytes(sig[len(sig)/2:])
+		if !ecdsa.Verify(k, digest, r, s) {
+			return errors.New("signature verification failed")
+		}
+		return nil
+	}
+}
+
+func jwtKeyTypeErr(alg string, key any) error {
+	return fmt.Errorf("key of type %T cannot be used with algorithm %q", key, alg)
+}
@@ -0,0 +1,55 @@
+package sbtest
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func signES(t *testing.T, key *ecdsa.PrivateKey, alg string, hash crypto.Hash, size int) string {
+	input := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"`+alg+`"}`)) +
+		"." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"a"}`))
+	h := hash.New()
+	h.Write([]byte(input))
+	r, s, err := ecdsa.Sign(rand.Reader, key, h.Sum(nil))
+	Nil(t, err)
+	sig := make([]byte, size)
+	r.FillBytes(sig[:size/2])
+	s.FillBytes(sig[size/2:])
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestJWTValidSignatureES(t *testing.T) {
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Nil(t, err)
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	Nil(t, err)
+	p521, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	Nil(t, err)
+
+	JWTValidSignature(t, signES(t, p256, "ES256", crypto.SHA256, 64), &p256.PublicKey)
+	JWTValidSignature(t, signES(t, p384, "ES384", crypto.SHA384, 96), &p384.PublicKey)
+	JWTValidSignature(t, signES(t, p521, "ES512", crypto.SHA512, 132), &p521.PublicKey)
+
+	token := signES(t, p256, "ES384", crypto.SHA384, 64)
+	msgs := recordFailures(t, func(t testing.TB) {
+		JWTValidSignature(t, token, &p256.PublicKey)
+	})
+	Eq(t, 1, len(msgs))
+	True(t, strings.Contains(
+		msgs[0], "algorithm ES384 requires a key on curve P-384, got P-256",
+	))
+
+	token = signES(t, p256, "ES256", crypto.SHA256, 66)
+	msgs = recordFailures(t, func(t testing.TB) {
+		JWTValidSignature(t, token, &p256.PublicKey)
+	})
+	Eq(t, 1, len(msgs))
+	True(t, strings.Contains(
+		msgs[0], "invalid signature length 66, algorithm ES256 requires 64",
+	))
+}
@@ -0,0 +1,395 @@
+package sbtest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type (
+	// A single log entry that was recorded by a [LogRecorder].
+	LogEntry struct {
+		Time  time.Time
+		Level slog.Level
+		Msg   string
+		// All attributes of the entry, including attributes added with
+		// [slog.Logger.With]. Attributes inside groups are keyed by the dot
+		// separated group names followed by the attribute key.
+		Attrs map[string]slog.Value
+	}
+
+	// A [slog.Handler] that records all entries it is given so that they can
+	// be asserted against. Entries written to the [io.Writer] returned by the
+	// Writer method are also recorded, allowing the standard log package to
+	// be captured. All methods are safe for concurrent use.
+	LogRecorder struct {
+		store  *logStore
+		level  slog.Leveler
+		groups []string
+		attrs  map[string]slog.Value
+	}
+
+	logStore struct {
+		mu      sync.Mutex
+		entries []LogEntry
+	}
+
+	logWriter struct {
+		store *logStore
+	}
+
+	// A condition on a single log entry that is used with [LoggedEntry].
+	LogMatcher struct {
+		desc  string
+		match func(e LogEntry) bool
+	}
+)
+
+const (
+	// The maximum number of entries that are listed when [LoggedEntry] fails.
+	maxNearestLogEntries = 3
+)
+
+// Creates a new log recorder that records entries at all levels.
+func NewLogRecorder() *LogRecorder {
+	return &LogRecorder{
+		store: &logStore{},
+		level: slog.Level(math.MinInt),
+		attrs: map[string]slog.Value{},
+	}
+}
+
+// Creates a new log recorder that only records entries at or above the
+// supplied level.
+func NewLogRecorderAtLevel(level slog.Leveler) *LogRecorder {
+	rv := NewLogRecorder()
+	rv.level = level
+	return rv
+}
+
+// Creates a [slog.Logger] that writes to the recorder.
+func (r *LogRecorder) Logger() *slog.Logger {
+	return slog.New(r)
+}
+
+// Returns an [io.Writer] that records every line written to it as a log entry
+// with level [slog.LevelInfo] and no attributes. This is intended to be used
+// with the standard log package, as shown below.
+//
+//	log.SetOutput(rec.Writer())
+func (r *LogRecorder) Writer() io.Writer {
+	return &logWriter{store: r.store}
+}
+
+// Returns a copy of all entries that have been recorded so far, in the order
+// they were recorded.
+func (r *LogRecorder) Entries() []LogEntry {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	rv := make([]LogEntry, len(r.store.entries))
+	copy(rv, r.store.entries)
+	return rv
+}
+
+// Removes all entries that have been recorded so far.
+func (r *LogRecorder) Reset() {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	r.store.entries = nil
+}
+
+// Implements the [slog.Handler] interface.
+func (r *LogRecorder) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= r.level.Level()
+}
+
+// Implements the [slog.Handler] interface.
+func (r *LogRecorder) Handle(_ context.Context, record slog.Record) error {
+	entry := LogEntry{
+		Time:  record.Time,
+		Level: record.Level,
+		Msg:   record.Message,
+		Attrs: make(map[string]slog.Value, len(r.attrs)+record.NumAttrs()),
+	}
+	for k, v := range r.attrs {
+		entry.Attrs[k] = v
+	}
+	prefix := strings.Join(r.groups, ".")
+	record.Attrs(func(a slog.Attr) bool {
+		addAttr(entry.Attrs, prefix, a)
+		return true
+	})
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	r.store.entries = append(r.store.entries, entry)
+	return nil
+}
+
+// Implements the [slog.Handler] interface.
+func (r *LogRecorder) WithAttrs(attrs []slog.Attr) slog.Handler {
+	rv := *r
+	rv.attrs = make(map[string]slog.Value, len(r.attrs)+len(attrs))
+	for k, v := range r.attrs {
+		rv.attrs[k] = v
+	}
+	prefix := strings.Join(r.groups, ".")
+	for _, a := range attrs {
+		addAttr(rv.attrs, prefix, a)
+	}
+	return &rv
+}
+
+// Implements the [slog.Handler] interface.
+func (r *LogRecorder) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return r
+	}
+	rv := *r
+	rv.groups = append(append([]string{}, r.groups...), name)
+	return &rv
+}
+
+func addAttr(attrs map[string]slog.Value, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	// Mirror the builtin handlers and ignore attrs with empty keys, unless
+	// they are groups that should be inlined
+	if a.Key == "" && a.Value.Kind() != slog.KindGroup {
+		return
+	}
+	key := a.Key
+	if prefix != "" && key != "" {
+		key = prefix + "." + key
+	} else if key == "" {
+		key = prefix
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		for _, ga := range a.Value.Group() {
+			addAttr(attrs, key, ga)
+		}
+		return
+	}
+	attrs[key] = a.Value
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	now := time.Now()
+	lines := strings.Split(strings.TrimSuffix(string(p), "\n"), "\n")
+
+	w.store.mu.Lock()
+	defer w.store.mu.Unlock()
+	for _, iterLine := range lines {
+		w.store.entries = append(w.store.entries, LogEntry{
+			Time:  now,
+			Level: slog.LevelInfo,
+			Msg:   iterLine,
+			Attrs: map[string]slog.Value{},
+		})
+	}
+	return len(p), nil
+}
+
+// Returns a single line representation of the entry containing its level,
+// message, and attributes sorted by key.
+func (e LogEntry) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %q", e.Level, e.Msg)
+	keys := make([]string, 0, len(e.Attrs))
+	for k := range e.Attrs {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&sb, " %s=%v", k, e.Attrs[k])
+	}
+	return sb.String()
+}
+
+// Tests that the recorder contains at least one entry at exactly the supplied
+// level whose message contains the supplied substring. All recorded entries
+// are listed on failure.
+func LoggedAtLevel(
+	t testing.TB,
+	rec *LogRecorder,
+	level slog.Level,
+	msgSubstr string,
+) {
+	t.Helper()
+	entries := rec.Entries()
+	for _, iterEntry := range entries {
+		if iterEntry.Level == level &&
+			strings.Contains(iterEntry.Msg, msgSubstr) {
+			return
+		}
+	}
+	f, line := callerLoc()
+	FormatError(
+		t, fmt.Sprintf("%s %q", level, msgSubstr), fmtLogEntries(entries),
+		"No log entry was found with the expected level and message.",
+		f, line,
+	)
+}
+
+// Tests that the recorder contains at least one entry with an attribute that
+// has the supplied key and value. Attributes inside groups are keyed by the
+// dot separated group names followed by the attribute key. Values are compared
+// with [slog.Value.Equal] after converting the expected value with
+// [slog.AnyValue], so an int will compare equal to the int64 slog stores.
+// Values of kind [slog.KindAny], such as slices and structs, are compared in
+// the same way as [MatchNested]. All recorded entries are listed on failure.
+func LoggedAttr(t testing.TB, rec *LogRecorder, key string, value any) {
+	t.Helper()
+	expected := slog.AnyValue(value).Resolve()
+	entries := rec.Entries()
+	for _, iterEntry := range entries {
+		if v, ok := iterEntry.Attrs[key]; ok && logValuesEq(expected, v) {
+			return
+		}
+	}
+	f, line := callerLoc()
+	FormatError(
+		t, fmt.Sprintf("%s=%v", key, expected), fmtLogEntries(entries),
+		"No log entry was found with the expected attribute.",
+		f, line,
+	)
+}
+
+// Tests that the recorder contains at least one entry that satisfies all of
+// the supplied matchers. This allows structured log expectations to be written
+// declaratively, as shown below.
+//
+//	sbtest.LoggedEntry(
+//		t, rec,
+//		sbtest.Level(slog.LevelWarn),
+//		sbtest.MsgContains("retry"),
+//		sbtest.Attr("attempt", 3),
+//	)
+//
+// On failure the entries that satisfied the most matchers are listed along
+// with the matchers they did not satisfy.
+func LoggedEntry(t testing.TB, rec *LogRecorder, matchers ...LogMatcher) {
+	t.Helper()
+	entries := rec.Entries()
+	failed := make([][]string, len(entries))
+	for i, iterEntry := range entries {
+		for _, iterMatcher := range matchers {
+			if !iterMatcher.match(iterEntry) {
+				failed[i] = append(failed[i], iterMatcher.desc)
+			}
+		}
+		if len(failed[i]) == 0 {
+			return
+		}
+	}
+
+	descs := make([]string, len(matchers))
+	for i, iterMatcher := range matchers {
+		descs[i] = iterMatcher.desc
+	}
+	nearest := make([]int, len(entries))
+	for i := range nearest {
+		nearest[i] = i
+	}
+	slices.SortStableFunc(nearest, func(l int, r int) int {
+		return len(failed[l]) - len(failed[r])
+	})
+	var sb strings.Builder
+	if len(entries) == 0 {
+		sb.WriteString(" <no entries>")
+	}
+	for _, iterIdx := range nearest[:min(len(nearest), maxNearestLogEntries)] {
+		fmt.Fprintf(
+			&sb, "\n  %d: %s\n    Failed: %s",
+			iterIdx, entries[iterIdx], strings.Join(failed[iterIdx], ", "),
+		)
+	}
+	f, line := callerLoc()
+	FormatError(
+		t,
+		strings.Join(descs, ", "),
+		fmt.Sprintf("%d non-matching entries", len(entries)),
+		"No log entry was found that satisfied all of the matchers.\nNearest Entries:"+
+			sb.String(),
+		f, line,
+	)
+}
+
+// Returns a matcher that is satisfied by entries at exactly the supplied level.
+func Level(level slog.Level) LogMatcher {
+	return LogMatcher{
+		desc:  fmt.Sprintf("Level(%s)", level),
+		match: func(e LogEntry) bool { return e.Level == level },
+	}
+}
+
+// Returns a matcher that is satisfied by entries whose message is equal to the
+// supplied message.
+func MsgEq(msg string) LogMatcher {
+	return LogMatcher{
+		desc:  fmt.Sprintf("MsgEq(%q)", msg),
+		match: func(e LogEntry) bool { return e.Msg == msg },
+	}
+}
+
+// Returns a matcher that is satisfied by entries whose message contains the
+// supplied substring.
+func MsgContains(substr string) LogMatcher {
+	return LogMatcher{
+		desc:  fmt.Sprintf("MsgContains(%q)", substr),
+		match: func(e LogEntry) bool { return strings.Contains(e.Msg, substr) },
+	}
+}
+
+// Returns a matcher that is satisfied by entries with an attribute that has
+// the supplied key and value. Keys and values are compared in the same way as
+// [LoggedAttr].
+func Attr(key string, value any) LogMatcher {
+	expected := slog.AnyValue(value).Resolve()
+	return LogMatcher{
+		desc: fmt.Sprintf("Attr(%s=%v)", key, expected),
+		match: func(e LogEntry) bool {
+			v, ok := e.Attrs[key]
+			return ok && logValuesEq(expected, v)
+		},
+	}
+}
+
+// Returns true if the supplied log values are equal. [slog.Value.Equal]
+// compares values of kind [slog.KindAny] with ==, which panics for
+// uncomparable types such as slices, so they are deeply compared instead.
+func logValuesEq(expected slog.Value, got slog.Value) bool {
+	if expected.Kind() != got.Kind() {
+		return false
+	}
+	switch expected.Kind() {
+	case slog.KindAny:
+		return deepEqual(expected.Any(), got.Any())
+	case slog.KindGroup:
+		return slices.EqualFunc(
+			expected.Group(), got.Group(),
+			func(l slog.Attr, r slog.Attr) bool {
+				return l.Key == r.Key && logValuesEq(l.Value, r.Value)
+			},
+		)
+	default:
+		return expected.Equal(got)
+	}
+}
+
+func fmtLogEntries(entries []LogEntry) string {
+	if len(entries) == 0 {
+		return "<no entries>"
+	}
+	var sb strings.Builder
+	for i, iterEntry := range entries {
+		fmt.Fprintf(&sb, "\n  %d: %s", i, iterEntry)
+	}
+	return sb.String()
+}
@@ -0,0 +1,50 @@
+package sbtest
+
+import (
+	"log/slog"
+	"testing"
+)
+
+func TestLoggedAttrUncomparable(t *testing.T) {
+	rec := NewLogRecorder()
+	rec.Logger().Info(
+		"msg",
+		"tags", []string{"a"},
+		"meta", map[string]int{"a": 1},
+		"n", 1,
+	)
+	LoggedAttr(t, rec, "tags", []string{"a"})
+	LoggedAttr(t, rec, "meta", map[string]int{"a": 1})
+	LoggedAttr(t, rec, "n", 1)
+
+	msgs := recordFailures(t, func(t testing.TB) {
+		LoggedAttr(t, rec, "tags", []string{"b"})
+	})
+	Eq(t, 1, len(msgs))
+}
+
+func TestLogValuesEq(t *testing.T) {
+	True(t, logValuesEq(slog.AnyValue([]int{1}), slog.AnyValue([]int{1})))
+	False(t, logValuesEq(slog.AnyValue([]int{1}), slog.AnyValue([]int{2})))
+	False(t, logValuesEq(slog.AnyValue([]int{1}), slog.IntValue(1)))
+	True(t, logValuesEq(slog.AnyValue(1), slog.Int64Value(1)))
+	True(t, logValuesEq(
+		slog.GroupValue(slog.Any("a", []int{1})),
+		slog.GroupValue(slog.Any("a", []int{1})),
+	))
+	False(t, logValuesEq(
+		slog.GroupValue(slog.Any("a", []int{1})),
+		slog.GroupValue(slog.Any("b", []int{1})),
+	))
+}
+
+func TestAttrMatcherUncomparable(t *testing.T) {
+	rec := NewLogRecorder()
+	rec.Logger().Warn("retry", "tags", []string{"a"})
+	LoggedEntry(t, rec, Level(slog.LevelWarn), Attr("tags", []string{"a"}))
+
+	msgs := recordFailures(t, func(t testing.TB) {
+		LoggedEntry(t, rec, Attr("tags", []string{"b"}))
+	})
+	Eq(t, 1, len(msgs))
+}
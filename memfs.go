@@ -0,0 +1,214 @@
+package sbtest
+
+import (
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+type (
+	// A writable, in memory filesystem that implements [fs.FS], along with
+	// [fs.ReadFileFS], [fs.ReadDirFS], and [fs.StatFS]. This allows code that
+	// is written against [fs.FS] to be tested without touching the disk.
+	// Parent directories are created implicitly when files are written. All
+	// methods are safe for concurrent use, and files that are already open are
+	// not affected by later writes.
+	MemFS struct {
+		mu    sync.Mutex
+		files fstest.MapFS
+	}
+)
+
+// Creates a new in memory filesystem that is populated with the supplied
+// files. The keys of the files map are slash separated paths and the values
+// are the contents of each file.
+func NewMemFS(files map[string]string) *MemFS {
+	rv := &MemFS{files: fstest.MapFS{}}
+	for name, contents := range files {
+		rv.files[name] = &fstest.MapFile{Data: []byte(contents), Mode: 0644}
+	}
+	return rv
+}
+
+// Writes the supplied data to the named file, replacing it if it already
+// exists. Returns an error if the name is not a valid path as defined by
+// [fs.ValidPath].
+func (m *MemFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if !fs.ValidPath(name) || name == "." {
+		return &fs.PathError{Op: "write", Path: name, Err: fs.ErrInvalid}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[name] = &fstest.MapFile{
+		Data:    slices.Clone(data),
+		Mode:    perm,
+		ModTime: time.Now(),
+	}
+	return nil
+}
+
+// Creates the named directory along with any parents that do not exist.
+// Returns an error if the name is not a valid path as defined by
+// [fs.ValidPath] or if a file already exists with the supplied name.
+func (m *MemFS) MkdirAll(name string, perm fs.FileMode) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrInvalid}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if info, err := m.files.Stat(name); err == nil {
+		if !info.IsDir() {
+			return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrExist}
+		}
+		return nil
+	}
+	m.files[name] = &fstest.MapFile{
+		Mode:    fs.ModeDir | perm,
+		ModTime: time.Now(),
+	}
+	return nil
+}
+
+// Removes the named file or directory along with everything inside it.
+// Returns an error if nothing exists with the supplied name.
+func (m *MemFS) RemoveAll(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	found := false
+	for iterName := range m.files {
+		if iterName == name || strings.HasPrefix(iterName, name+"/") {
+			delete(m.files, iterName)
+			found = true
+		}
+	}
+	if !found {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	return nil
+}
+
+// Implements the [fs.FS] interface.
+func (m *MemFS) Open(name string) (fs.File, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.files.Open(name)
+}
+
+// Implements the [fs.ReadFileFS] interface.
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.files.ReadFile(name)
+}
+
+// Implements the [fs.ReadDirFS] interface.
+func (m *MemFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.files.ReadDir(name)
+}
+
+// Implements the [fs.StatFS] interface.
+func (m *MemFS) Stat(name string) (fs.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.files.Stat(name)
+}
+
+// Tests that the file at the supplied path in the filesystem exists and that
+// its contents are equal to the expected contents. A line based diff of the
+// contents is shown on failure.
+func FSContains(t testing.TB, fsys fs.FS, path string, contents string) {
+	t.Helper()
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		f, line := callerLoc()
+		FormatError(
+			t, nil, errChain(err),
+			fmt.Sprintf("The file could not be read | Path: %s", path),
+			f, line,
+		)
+		return
+	}
+	if string(data) != contents {
+		f, line := callerLoc()
+		FormatError(
+			t, contents, string(data),
+			fmt.Sprintf(
+				"The file contents did not match | Path: %s\nDiff:%s",
+				path, lineDiff(contents, string(data)),
+			),
+			f, line,
+		)
+	}
+}
+
+// Tests that the filesystem contains exactly the expected files. The keys of
+// the expected map are slash separated paths and the values are the contents
+// of each file. Directories are not compared, only the regular files inside
+// them. All missing, unexpected, and differing files are listed on failure.
+func FSMatch(t testing.TB, expected map[string]string, fsys fs.FS) {
+	t.Helper()
+	got := map[string]string{}
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		data, err := fs.ReadFile(fsys, path)
+		got[path] = string(data)
+		return err
+	})
+	if err != nil {
+		f, line := callerLoc()
+		FormatError(
+			t, nil, errChain(err),
+			"The filesystem could not be walked.",
+			f, line,
+		)
+		return
+	}
+
+	names := []string{}
+	for name := range expected {
+		names = append(names, name)
+	}
+	for name := range got {
+		if _, ok := expected[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	var sb strings.Builder
+	for _, iterName := range names {
+		e, eOk := expected[iterName]
+		g, gOk := got[iterName]
+		switch {
+		case !gOk:
+			fmt.Fprintf(&sb, "\n  Missing file: %s", iterName)
+		case !eOk:
+			fmt.Fprintf(&sb, "\n  Unexpected file: %s", iterName)
+		case e != g:
+			fmt.Fprintf(
+				&sb, "\n  Differing file: %s%s",
+				iterName, strings.ReplaceAll(lineDiff(e, g), "\n", "\n    "),
+			)
+		}
+	}
+	if sb.Len() > 0 {
+		f, line := callerLoc()
+		FormatError(
+			t, len(expected), len(got),
+			fmt.Sprintf(
+				"The filesystem did not contain the expected files.\nDifferences:%s",
+				sb.String(),
+			),
+			f, line,
+		)
+	}
+}
@@ -0,0 +1,147 @@
+package sbtest
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+const (
+	// The maximum number of bytes of a multipart body that are kept in memory
+	// when it is parsed. Larger file parts are stored in temporary files that
+	// are removed when the test finishes.
+	multipartMaxMemory = 32 << 20
+)
+
+// Tests that the multipart form body of the supplied request contains a value
+// for the supplied field that is equal to expected. If the field has multiple
+// values any one of them may match. All values of the field are shown on
+// failure.
+//
+// The body is only parsed the first time an assertion is made against the
+// request. The parsed form is stored in the request's MultipartForm field, so
+// multiple assertions can be made against the same request and requests whose
+// form was already parsed by a handler can be used directly.
+func MultipartContains(t testing.TB, req *http.Request, field string, expected string) {
+	t.Helper()
+	f, line := callerLoc()
+	form, ok := parseMultipart(t, req, f, line)
+	if !ok {
+		return
+	}
+	values, ok := form.Value[field]
+	if !ok {
+		FormatError(
+			t, expected, nil,
+			fmt.Sprintf("The multipart form did not contain the field | Field: %s", field),
+			f, line,
+		)
+		return
+	}
+	if !slices.Contains(values, expected) {
+		FormatError(
+			t, expected, values,
+			fmt.Sprintf(
+				"The multipart form field did not contain the expected value | Field: %s",
+				field,
+			),
+			f, line,
+		)
+	}
+}
+
+// Tests that the multipart form body of the supplied request contains a file
+// part for the supplied field with the expected file name and contents. If the
+// field has multiple file parts the first one is used. The body is parsed in
+// the same way as [MultipartContains]. On failure the contents are shown as a
+// hex dump in the same way as [EqBytes].
+func MultipartFileEq(
+	t testing.TB,
+	req *http.Request,
+	field string,
+	expectedFilename string,
+	expected []byte,
+) {
+	t.Helper()
+	f, line := callerLoc()
+	form, ok := parseMultipart(t, req, f, line)
+	if !ok {
+		return
+	}
+	files := form.File[field]
+	if len(files) == 0 {
+		FormatError(
+			t, expectedFilename, nil,
+			fmt.Sprintf("The multipart form did not contain a file for the field | Field: %s", field),
+			f, line,
+		)
+		return
+	}
+	if files[0].Filename != expectedFilename {
+		FormatError(
+			t, expectedFilename, files[0].Filename,
+			fmt.Sprintf("The file part did not have the expected file name | Field: %s", field),
+			f, line,
+		)
+		return
+	}
+
+	file, err := files[0].Open()
+	if err != nil {
+		FormatError(
+			t, nil, errChain(err),
+			fmt.Sprintf("The file part could not be opened | Field: %s", field),
+			f, line,
+		)
+		return
+	}
+	defer file.Close()
+	got, err := io.ReadAll(file)
+	if err != nil {
+		FormatError(
+			t, nil, errChain(err),
+			fmt.Sprintf("The file part could not be read | Field: %s", field),
+			f, line,
+		)
+		return
+	}
+	bytesEq(
+		t, expected, got,
+		fmt.Sprintf("The file part did not have the expected contents | Field: %s", field),
+		f, line,
+	)
+}
+
+func parseMultipart(
+	t testing.TB,
+	req *http.Request,
+	f string,
+	line int,
+) (*multipart.Form, bool) {
+	t.Helper()
+	if req == nil {
+		FormatError(
+			t, "a request", nil,
+			"The supplied request was nil.",
+			f, line,
+		)
+		return nil, false
+	}
+	if req.MultipartForm != nil {
+		return req.MultipartForm, true
+	}
+	if err := req.ParseMultipartForm(multipartMaxMemory); err != nil {
+		FormatError(
+			t, nil, errChain(err),
+			"The request body could not be parsed as a multipart form.",
+			f, line,
+		)
+		return nil, false
+	}
+	form := req.MultipartForm
+	t.Cleanup(func() { form.RemoveAll() })
+	return form, true
+}
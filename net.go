@@ -0,0 +1,259 @@
+package sbtest
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+type (
+	// A fake network server started by [StartTCPServer] or [StartUDPServer].
+	// The server records every byte it receives and the number of connections
+	// it has accepted so that assertions can be made about the behavior of the
+	// network client under test. All methods are safe for concurrent use.
+	NetServer struct {
+		// The address the server is listening on in host:port form.
+		Addr string
+
+		mu       sync.Mutex
+		changed  chan struct{}
+		conns    int
+		received []byte
+	}
+
+	// A [net.Conn] that records every byte read from it with the server that
+	// accepted it.
+	recordingConn struct {
+		net.Conn
+		srv *NetServer
+	}
+)
+
+// Starts a TCP server on a random local port that calls the supplied handler
+// in a new goroutine for every connection it accepts. The connection is closed
+// once the handler returns. Every byte the handler reads from the connection
+// is recorded with the server. If the handler is nil every connection is read
+// until the client closes it. The listener and all open connections are closed
+// when the test completes.
+//
+//	srv := sbtest.StartTCPServer(t, func(conn net.Conn) {
+//		conn.Write([]byte("hello\n"))
+//	})
+//	client := NewClient(srv.Addr)
+func StartTCPServer(t testing.TB, handler func(conn net.Conn)) *NetServer {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		f, line := callerLoc()
+		FormatError(
+			t, nil, errChain(err),
+			"The TCP listener could not be created.",
+			f, line,
+		)
+		return nil
+	}
+	if handler == nil {
+		handler = func(conn net.Conn) {
+			buf := make([]byte, 4096)
+			for {
+				if _, err := conn.Read(buf); err != nil {
+					return
+				}
+			}
+		}
+	}
+
+	rv := &NetServer{Addr: lis.Addr().String(), changed: make(chan struct{})}
+	var wg sync.WaitGroup
+	var connsMu sync.Mutex
+	open := map[net.Conn]struct{}{}
+	// Set by the cleanup so that a connection accepted while the cleanup is
+	// closing the open connections is closed rather than left open.
+	closed := false
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			connsMu.Lock()
+			if closed {
+				connsMu.Unlock()
+				conn.Close()
+				continue
+			}
+			open[conn] = struct{}{}
+			connsMu.Unlock()
+			rv.update(func() { rv.conns++ })
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() {
+					connsMu.Lock()
+					delete(open, conn)
+					connsMu.Unlock()
+					conn.Close()
+				}()
+				handler(&recordingConn{Conn: conn, srv: rv})
+			}()
+		}
+	}()
+	t.Cleanup(func() {
+		lis.Close()
+		connsMu.Lock()
+		closed = true
+		for iterConn := range open {
+			iterConn.Close()
+		}
+		connsMu.Unlock()
+		wg.Wait()
+	})
+	return rv
+}
+
+// Starts a UDP server on a random local port that calls the supplied handler
+// with every datagram it receives. If the handler returns a non-nil slice it
+// is sent back to the address the datagram came from. Every datagram is
+// recorded with the server and counted as a connection. If the handler is nil
+// datagrams are recorded but never answered. The server is closed when the
+// test completes.
+func StartUDPServer(
+	t testing.TB,
+	handler func(data []byte, addr net.Addr) []byte,
+) *NetServer {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		f, line := callerLoc()
+		FormatError(
+			t, nil, errChain(err),
+			"The UDP listener could not be created.",
+			f, line,
+		)
+		return nil
+	}
+
+	rv := &NetServer{Addr: pc.LocalAddr().String(), changed: make(chan struct{})}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		buf := make([]byte, 65535)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if errors.Is(err, net.ErrClosed) {
+				return
+			} else if err != nil {
+				continue
+			}
+			data := append([]byte{}, buf[:n]...)
+			rv.update(func() {
+				rv.conns++
+				rv.received = append(rv.received, data...)
+			})
+			if handler == nil {
+				continue
+			}
+			if resp := handler(data, addr); resp != nil {
+				pc.WriteTo(resp, addr)
+			}
+		}
+	}()
+	t.Cleanup(func() {
+		pc.Close()
+		<-done
+	})
+	return rv
+}
+
+// Returns a copy of all bytes the server has received so far, across all
+// connections, in the order they were read.
+func (s *NetServer) Received() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]byte{}, s.received...)
+}
+
+// Returns the number of connections the server has accepted so far.
+func (s *NetServer) ConnCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conns
+}
+
+// Tests that the server receives exactly the expected bytes, across all
+// connections, within the supplied timeout. The assertion waits until at least
+// len(expected) bytes have been received or the timeout expires. On failure
+// the bytes are shown in the same way as [EqBytes].
+func (s *NetServer) ReceivedEq(t testing.TB, expected []byte, timeout time.Duration) {
+	t.Helper()
+	f, line := callerLoc()
+	s.waitFor(timeout, func() bool { return len(s.received) >= len(expected) })
+	bytesEq(
+		t, expected, s.Received(),
+		fmt.Sprintf("The server did not receive the expected bytes | Timeout: %s", timeout),
+		f, line,
+	)
+}
+
+// Tests that the server accepts exactly n connections within the supplied
+// timeout. The assertion waits until at least n connections have been accepted
+// or the timeout expires.
+func (s *NetServer) ConnCountEq(t testing.TB, n int, timeout time.Duration) {
+	t.Helper()
+	s.waitFor(timeout, func() bool { return s.conns >= n })
+	if got := s.ConnCount(); got != n {
+		f, line := callerLoc()
+		FormatError(
+			t, n, got,
+			fmt.Sprintf(
+				"The server did not accept the expected number of connections | Timeout: %s",
+				timeout,
+			),
+			f, line,
+		)
+	}
+}
+
+// Runs fn while holding the lock and notifies all waiters.
+func (s *NetServer) update(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn()
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// Waits until cond returns true or the timeout expires. Cond is called while
+// holding the lock.
+func (s *NetServer) waitFor(timeout time.Duration, cond func() bool) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		s.mu.Lock()
+		ok, changed := cond(), s.changed
+		s.mu.Unlock()
+		if ok {
+			return
+		}
+		select {
+		case <-changed:
+		case <-timer.C:
+			return
+		}
+	}
+}
+
+// Implements the [io.Reader] interface, recording all bytes that are read.
+func (c *recordingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.srv.update(func() { c.srv.received = append(c.srv.received, b[:n]...) })
+	}
+	return n, err
+}
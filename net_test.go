@@ -0,0 +1,73 @@
+package sbtest
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTCPServerReceivedEq(t *testing.T) {
+	srv := StartTCPServer(t, nil)
+	conn, err := net.Dial("tcp", srv.Addr)
+	Nil(t, err)
+	_, err = conn.Write([]byte("hi"))
+	Nil(t, err)
+	srv.ReceivedEq(t, []byte("hi"), time.Second)
+	srv.ConnCountEq(t, 1, time.Second)
+
+	msgs := recordFailures(t, func(t testing.TB) {
+		srv.ReceivedEq(t, []byte("hello"), 10*time.Millisecond)
+	})
+	Eq(t, 1, len(msgs))
+	True(t, strings.Contains(
+		msgs[0], "The server did not receive the expected bytes | Timeout: 10ms",
+	))
+	msgs = recordFailures(t, func(t testing.TB) {
+		srv.ConnCountEq(t, 2, 10*time.Millisecond)
+	})
+	Eq(t, 1, len(msgs))
+	True(t, strings.Contains(
+		msgs[0], "did not accept the expected number of connections",
+	))
+	conn.Close()
+}
+
+func TestTCPServerCleanupWhileDialing(t *testing.T) {
+	for range 50 {
+		stop := make(chan struct{})
+		var wg sync.WaitGroup
+		cleaned := make(chan struct{})
+		go func() {
+			defer close(cleaned)
+			t.Run("server", func(t *testing.T) {
+				srv := StartTCPServer(t, nil)
+				for range 8 {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						for {
+							select {
+							case <-stop:
+								return
+							default:
+							}
+							// Connections are left open so only the server
+							// can close them.
+							net.Dial("tcp", srv.Addr)
+						}
+					}()
+				}
+				time.Sleep(100 * time.Microsecond)
+			})
+		}()
+		select {
+		case <-cleaned:
+		case <-time.After(10 * time.Second):
+			t.Fatal("the server cleanup did not return")
+		}
+		close(stop)
+		wg.Wait()
+	}
+}
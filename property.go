@@ -0,0 +1,134 @@
+package sbtest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type (
+	// Collects the failures of a single run of a property. Fatal failures stop
+	// the run without stopping the parent test, in the same way as [R].
+	propertyTB struct {
+		testing.TB
+		rec *failureRecorder
+	}
+)
+
+// Tests that the supplied property holds for runs randomly generated values of
+// type T. Values are generated with [Arbitrary] from a [Rand] created with
+// [NewRand], so the same values are generated every time the test runs and the
+// seed is logged on failure. The property makes assertions on the value using
+// the supplied [testing.TB], any failure or panic means the property did not
+// hold for that value. Skipping with the [testing.TB] discards the value.
+//
+//	sbtest.Property(t, 100, func(t testing.TB, v []int) {
+//		sbtest.SlicesMatch(t, v, Reverse(Reverse(v)))
+//	})
+//
+// When a value is found for which the property does not hold it is minimized
+// with [Shrink] and the test fails with both the original and the minimized
+// value, along with the failures of the property for the minimized value.
+func Property[T any](t testing.TB, runs int, prop func(t testing.TB, v T)) {
+	t.Helper()
+	f, line := callerLoc()
+	r := NewRand(t)
+	for i := range runs {
+		v := Arbitrary[T](r)
+		msgs := runProperty(t, v, prop)
+		if len(msgs) == 0 {
+			continue
+		}
+		minimized, steps := Shrink(v, func(v T) bool {
+			if m := runProperty(t, v, prop); len(m) > 0 {
+				msgs = m
+				return true
+			}
+			return false
+		})
+		FormatError(
+			t, "the property to hold", minimized,
+			fmt.Sprintf(
+				"The property did not hold | Run: %d/%d | Shrink Steps: %d"+
+					"\nOriginal: %+v\nMinimized: %+v\nFailures:%s",
+				i+1, runs, steps, v, minimized, fmtPropertyFailures(msgs),
+			),
+			f, line,
+		)
+		return
+	}
+}
+
+// Runs the property for the supplied value and returns its failures.
+func runProperty[T any](
+	t testing.TB,
+	v T,
+	prop func(t testing.TB, v T),
+) []string {
+	return recordFailures(t, func(t testing.TB) { prop(t, v) })
+}
+
+// Runs the supplied function with a [testing.TB] that records failures instead
+// of reporting them, and returns the failures. A panic is recorded as a
+// failure.
+func recordFailures(t testing.TB, fn func(t testing.TB)) []string {
+	p := &propertyTB{TB: t, rec: &failureRecorder{}}
+	p.rec.run(func() {
+		defer func() {
+			if r := recover(); r != nil {
+				p.rec.record(fmt.Sprintf("panic: %v", r))
+			}
+		}()
+		fn(p)
+	})
+	if p.rec.Failed() && len(p.rec.Msgs()) == 0 {
+		return []string{"the run failed without a message"}
+	}
+	return p.rec.Msgs()
+}
+
+func fmtPropertyFailures(msgs []string) string {
+	var sb strings.Builder
+	for _, iterMsg := range msgs {
+		sb.WriteString("\n  ")
+		sb.WriteString(strings.ReplaceAll(iterMsg, "\n", "\n  "))
+	}
+	return sb.String()
+}
+
+// Records the failure and stops the current run.
+func (p *propertyTB) Fatal(args ...any) { p.rec.Fatal(args...) }
+
+// Records the failure and stops the current run.
+func (p *propertyTB) Fatalf(format string, args ...any) { p.rec.Fatalf(format, args...) }
+
+// Marks the current run as failed and stops it.
+func (p *propertyTB) FailNow() { p.rec.FailNow() }
+
+// Records the failure and continues the current run.
+func (p *propertyTB) Error(args ...any) { p.rec.Error(args...) }
+
+// Records the failure and continues the current run.
+func (p *propertyTB) Errorf(format string, args ...any) { p.rec.Errorf(format, args...) }
+
+// Marks the current run as failed and continues it.
+func (p *propertyTB) Fail() { p.rec.Fail() }
+
+// Returns true if the current run has failed.
+func (p *propertyTB) Failed() bool { return p.rec.Failed() }
+
+// Stops the current run without failing it, discarding the value.
+func (p *propertyTB) Skip(args ...any) { p.rec.Skip(args...) }
+
+// Stops the current run without failing it, discarding the value.
+func (p *propertyTB) Skipf(format string, args ...any) { p.rec.Skipf(format, args...) }
+
+// Stops the current run without failing it, discarding the value.
+func (p *propertyTB) SkipNow() { p.rec.SkipNow() }
+
+// Returns true if the current run was skipped.
+func (p *propertyTB) Skipped() bool { return p.rec.Skipped() }
+
+func (p *propertyTB) recorder() *failureRecorder { return p.rec }
+
+func (p *propertyTB) wrapped() testing.TB { return p.TB }
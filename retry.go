@@ -0,0 +1,228 @@
+package sbtest
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type (
+	// Collects the failures of a single attempt of a [RetryTest]. R implements
+	// [testing.TB] so it can be passed to any assertion in this package. Any
+	// method that would normally stop the test, such as Fatal or FailNow,
+	// instead records the failure and stops the current attempt. Skip,
+	// Skipf, and SkipNow stop the current attempt and skip the parent test
+	// once the attempt has stopped. All other methods are passed through to
+	// the parent test.
+	R struct {
+		testing.TB
+		rec *failureRecorder
+	}
+
+	// Records failures instead of reporting them to a test. Shared by all the
+	// [testing.TB] implementations in this package that defer reporting
+	// failures until a later point.
+	failureRecorder struct {
+		mu      sync.Mutex
+		failed  bool
+		msgs    []string
+		skipped bool
+		skipMsg string
+	}
+
+	// Implemented by the [testing.TB] implementations in this package that
+	// record failures with a [failureRecorder] instead of reporting them to
+	// the parent test.
+	recordingTB interface {
+		recorder() *failureRecorder
+	}
+)
+
+// Runs fn up to attempts times, waiting for backoff between each attempt. The
+// test passes as soon as one attempt completes without any failures. If every
+// attempt fails then the test fails with a report that contains the failures
+// from each attempt. This is intended for tests that depend on external
+// resources that may be temporarily unavailable, it should not be used to hide
+// genuinely flaky tests.
+//
+// Each attempt is run in its own goroutine so that a fatal failure can stop the
+// attempt without stopping the parent test. Panics if attempts is less than
+// one.
+func RetryTest(
+	t testing.TB,
+	attempts int,
+	backoff time.Duration,
+	fn func(r *R),
+) {
+	t.Helper()
+	if attempts < 1 {
+		panic(fmt.Sprintf(
+			"sbtest: RetryTest requires at least one attempt, got %d", attempts,
+		))
+	}
+	f, line := callerLoc()
+	failures := make([][]string, 0, attempts)
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			time.Sleep(backoff)
+		}
+		r := &R{TB: t, rec: &failureRecorder{}}
+		r.rec.run(func() { fn(r) })
+		if r.rec.Skipped() {
+			t.Skip(r.rec.SkipMsg())
+		}
+		if !r.rec.Failed() {
+			return
+		}
+		failures = append(failures, r.rec.Msgs())
+	}
+
+	var sb strings.Builder
+	for i, iterMsgs := range failures {
+		fmt.Fprintf(&sb, "\nAttempt %d:", i+1)
+		for _, iterMsg := range iterMsgs {
+			sb.WriteString("\n  ")
+			sb.WriteString(strings.ReplaceAll(iterMsg, "\n", "\n  "))
+		}
+	}
+	FormatError(
+		t,
+		"at least one passing attempt",
+		fmt.Sprintf("%d failed attempts", attempts),
+		fmt.Sprintf("Every attempt failed.%s", sb.String()),
+		f, line,
+	)
+}
+
+// Records the failure and stops the current attempt.
+func (r *R) Fatal(args ...any) { r.rec.Fatal(args...) }
+
+// Records the failure and stops the current attempt.
+func (r *R) Fatalf(format string, args ...any) { r.rec.Fatalf(format, args...) }
+
+// Marks the current attempt as failed and stops it.
+func (r *R) FailNow() { r.rec.FailNow() }
+
+// Records the failure and continues the current attempt.
+func (r *R) Error(args ...any) { r.rec.Error(args...) }
+
+// Records the failure and continues the current attempt.
+func (r *R) Errorf(format string, args ...any) { r.rec.Errorf(format, args...) }
+
+// Marks the current attempt as failed and continues it.
+func (r *R) Fail() { r.rec.Fail() }
+
+// Returns true if the current attempt has failed.
+func (r *R) Failed() bool { return r.rec.Failed() }
+
+// Stops the current attempt and skips the parent test.
+func (r *R) Skip(args ...any) { r.rec.Skip(args...) }
+
+// Stops the current attempt and skips the parent test.
+func (r *R) Skipf(format string, args ...any) { r.rec.Skipf(format, args...) }
+
+// Stops the current attempt and skips the parent test.
+func (r *R) SkipNow() { r.rec.SkipNow() }
+
+// Returns true if the current attempt was skipped.
+func (r *R) Skipped() bool { return r.rec.Skipped() }
+
+func (r *R) recorder() *failureRecorder { return r.rec }
+
+func (r *R) wrapped() testing.TB { return r.TB }
+
+// Runs the supplied function in a new goroutine and waits for it to finish.
+// This allows [runtime.Goexit] to be used to stop the function.
+func (f *failureRecorder) run(fn func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+	<-done
+}
+
+func (f *failureRecorder) Fatal(args ...any) {
+	f.Error(args...)
+	runtime.Goexit()
+}
+
+func (f *failureRecorder) Fatalf(format string, args ...any) {
+	f.Errorf(format, args...)
+	runtime.Goexit()
+}
+
+func (f *failureRecorder) FailNow() {
+	f.Fail()
+	runtime.Goexit()
+}
+
+func (f *failureRecorder) Error(args ...any) {
+	f.record(fmt.Sprint(args...))
+}
+
+func (f *failureRecorder) Errorf(format string, args ...any) {
+	f.record(fmt.Sprintf(format, args...))
+}
+
+func (f *failureRecorder) Fail() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failed = true
+}
+
+func (f *failureRecorder) Failed() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.failed
+}
+
+func (f *failureRecorder) Msgs() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string{}, f.msgs...)
+}
+
+func (f *failureRecorder) Skip(args ...any) {
+	f.skip(fmt.Sprint(args...))
+}
+
+func (f *failureRecorder) Skipf(format string, args ...any) {
+	f.skip(fmt.Sprintf(format, args...))
+}
+
+func (f *failureRecorder) SkipNow() { f.skip("") }
+
+func (f *failureRecorder) Skipped() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.skipped
+}
+
+// Returns the message of the first skip, or an empty string if the skip had no
+// message.
+func (f *failureRecorder) SkipMsg() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.skipMsg
+}
+
+// Records the skip and stops the calling goroutine.
+func (f *failureRecorder) skip(msg string) {
+	f.mu.Lock()
+	if !f.skipped {
+		f.skipped, f.skipMsg = true, msg
+	}
+	f.mu.Unlock()
+	runtime.Goexit()
+}
+
+func (f *failureRecorder) record(msg string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failed = true
+	f.msgs = append(f.msgs, msg)
+}
@@ -0,0 +1,73 @@
+package sbtest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRetryTestAttempts(t *testing.T) {
+	Panics(t, func() { RetryTest(t, 0, 0, func(r *R) {}) })
+	Panics(t, func() { RetryTest(t, -1, 0, func(r *R) {}) })
+
+	calls := 0
+	RetryTest(t, 3, 0, func(r *R) {
+		calls++
+		Eq(r, 2, calls)
+	})
+	Eq(t, 2, calls)
+}
+
+func TestRetryTestEveryAttemptFailed(t *testing.T) {
+	msgs := recordFailures(t, func(t testing.TB) {
+		RetryTest(t, 2, 0, func(r *R) { r.Fatal("unavailable") })
+	})
+	Eq(t, 1, len(msgs))
+	True(t, strings.Contains(
+		msgs[0], "Every attempt failed.\nAttempt 1:\n  unavailable\nAttempt 2:\n  unavailable",
+	))
+}
+
+func TestRetryTestSkip(t *testing.T) {
+	calls := 0
+	var sub *testing.T
+	t.Run("skipped", func(t *testing.T) {
+		sub = t
+		RetryTest(t, 3, 0, func(r *R) {
+			calls++
+			r.Skip("no database")
+		})
+		t.Error("the test was not skipped")
+	})
+	True(t, sub.Skipped())
+	Eq(t, 1, calls)
+}
+
+func TestCollectorSkip(t *testing.T) {
+	var sub *testing.T
+	t.Run("skipped", func(t *testing.T) {
+		sub = t
+		c := NewCollector(t)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			c.Skipf("no %s", "network")
+			t.Error("the goroutine was not stopped")
+		}()
+		<-done
+		True(t, c.Skipped())
+		c.Flush()
+		t.Error("the test was not skipped")
+	})
+	True(t, sub.Skipped())
+}
+
+func TestPropertySkipDiscardsValue(t *testing.T) {
+	msgs := recordFailures(t, func(t testing.TB) {
+		Property(t, 20, func(t testing.TB, v int) {
+			if v%2 == 0 {
+				t.SkipNow()
+			}
+		})
+	})
+	Eq(t, 0, len(msgs))
+}
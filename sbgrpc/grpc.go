@@ -0,0 +1,113 @@
+// Helpers for testing gRPC services in process over an in memory listener, and
+// assertions on the status codes and responses the services return.
+package sbgrpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+	"github.com/barbell-math/smoothbrain-test/sbproto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+// The size of the in memory buffer used by the connection between the client
+// and server created by [StartGRPCServer].
+const bufSize = 1024 * 1024
+
+// Starts a gRPC server that listens on an in memory connection and returns a
+// client connection to it. The register function is called with the server
+// before it starts serving so that services can be registered on it. The
+// client connection is closed and the server is stopped when the test
+// completes.
+//
+//	conn := sbgrpc.StartGRPCServer(t, func(s *grpc.Server) {
+//		pb.RegisterGreeterServer(s, &greeter{})
+//	})
+//	client := pb.NewGreeterClient(conn)
+func StartGRPCServer(
+	t testing.TB,
+	register func(s *grpc.Server),
+	opts ...grpc.ServerOption,
+) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(bufSize)
+	srv := grpc.NewServer(opts...)
+	register(srv)
+	go srv.Serve(lis)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		srv.Stop()
+		f, line := sbtest.CallerLoc()
+		sbtest.FormatError(
+			t, nil, err,
+			"The client connection could not be created.",
+			f, line,
+		)
+		return nil
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		srv.Stop()
+	})
+	return conn
+}
+
+// Tests that the supplied error has the expected gRPC status code. A nil error
+// has the code [codes.OK].
+func CodeEq(t testing.TB, expected codes.Code, err error) {
+	t.Helper()
+	if got := status.Code(err); got != expected {
+		f, line := sbtest.CallerLoc()
+		sbtest.FormatError(
+			t, expected, got,
+			fmt.Sprintf(
+				"The error did not have the expected status code | Error: %v",
+				err,
+			),
+			f, line,
+		)
+	}
+}
+
+// Tests that the supplied error has the expected gRPC status code and message.
+func StatusEq(
+	t testing.TB,
+	expectedCode codes.Code,
+	expectedMsg string,
+	err error,
+) {
+	t.Helper()
+	s := status.Convert(err)
+	if s.Code() != expectedCode || s.Message() != expectedMsg {
+		f, line := sbtest.CallerLoc()
+		sbtest.FormatError(
+			t,
+			fmt.Sprintf("%s: %s", expectedCode, expectedMsg),
+			fmt.Sprintf("%s: %s", s.Code(), s.Message()),
+			"The error did not have the expected status.",
+			f, line,
+		)
+	}
+}
+
+// Tests that the supplied response messages are equal. Refer to
+// [sbproto.EqProto] for the equality rules.
+func ResponseEq(t testing.TB, expected proto.Message, got proto.Message) {
+	t.Helper()
+	sbproto.EqProto(t, expected, got)
+}
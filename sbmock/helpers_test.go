@@ -0,0 +1,59 @@
+package sbmock
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+type recordingTB struct {
+	testing.TB
+	mu   sync.Mutex
+	msgs []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Fatal(args ...any) {
+	r.Error(args...)
+	runtime.Goexit()
+}
+
+func (r *recordingTB) Error(args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.msgs = append(r.msgs, fmt.Sprint(args...))
+}
+
+// Runs fn with a test that records failures instead of reporting them, and
+// returns the recorded failures. Cleanups registered by fn are run before
+// returning.
+func recordFailures(fn func(t testing.TB)) []string {
+	r := &recordingTB{}
+	var cleanups []func()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(&cleanupTB{recordingTB: r, cleanups: &cleanups})
+	}()
+	<-done
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		ran := make(chan struct{})
+		go func() {
+			defer close(ran)
+			cleanups[i]()
+		}()
+		<-ran
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.msgs
+}
+
+type cleanupTB struct {
+	*recordingTB
+	cleanups *[]func()
+}
+
+func (c *cleanupTB) Cleanup(fn func()) { *c.cleanups = append(*c.cleanups, fn) }
@@ -0,0 +1,163 @@
+package sbmock
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type (
+	// Matches a single argument of a call to a mock. A Matcher can be passed
+	// in place of any argument to [Mock.On] so the expected call matches
+	// arguments that are not exactly equal to a known value.
+	Matcher interface {
+		// Returns true if the argument matches.
+		Match(arg any) bool
+		// Describes the arguments that match, as shown in failures.
+		String() string
+	}
+
+	// Implemented by matchers that record the arguments of matched calls.
+	capturer interface {
+		capture(arg any)
+	}
+
+	anyMatcher[T any] struct{}
+
+	predMatcher[T any] struct {
+		desc string
+		pred func(v T) bool
+	}
+
+	// A [Matcher] that matches any argument of type T and records the
+	// argument of every call that matched the expected call it was passed to,
+	// so the arguments can later be inspected with the normal sbtest
+	// assertions. Create one with [Captor].
+	//
+	//	c := sbmock.Captor[Request]()
+	//	m.On("Send", c)
+	//	...
+	//	sbtest.Eq(t, "GET", c.Last().Method)
+	ArgCaptor[T any] struct {
+		mu     sync.Mutex
+		values []T
+	}
+)
+
+// Returns a matcher that matches any argument that is a T. For an interface
+// type T a nil argument also matches.
+func Any[T any]() Matcher {
+	return anyMatcher[T]{}
+}
+
+// Returns a matcher that matches any argument that is a T for which the
+// supplied predicate returns true. The optional descriptions are joined and
+// shown in failures in place of the argument. Without a description the type
+// of the predicate is shown.
+//
+//	m.On("Get", sbmock.MatchedBy(func(k string) bool {
+//		return k != ""
+//	}, "non-empty key"))
+func MatchedBy[T any](pred func(v T) bool, desc ...string) Matcher {
+	rv := predMatcher[T]{desc: strings.Join(desc, " "), pred: pred}
+	if rv.desc == "" {
+		rv.desc = reflect.TypeOf(pred).String()
+	}
+	return rv
+}
+
+// Creates a new captor of arguments of type T that has not captured any
+// arguments.
+func Captor[T any]() *ArgCaptor[T] {
+	return &ArgCaptor[T]{}
+}
+
+// Implements the [Matcher] interface.
+func (anyMatcher[T]) Match(arg any) bool {
+	_, ok := asArg[T](arg)
+	return ok
+}
+
+// Implements the [Matcher] interface.
+func (anyMatcher[T]) String() string {
+	return fmt.Sprintf("Any[%s]", reflect.TypeFor[T]())
+}
+
+// Implements the [Matcher] interface.
+func (p predMatcher[T]) Match(arg any) bool {
+	v, ok := asArg[T](arg)
+	return ok && p.pred(v)
+}
+
+// Implements the [Matcher] interface.
+func (p predMatcher[T]) String() string {
+	return fmt.Sprintf("MatchedBy(%s)", p.desc)
+}
+
+// Implements the [Matcher] interface. Matches any argument that is a T.
+func (c *ArgCaptor[T]) Match(arg any) bool {
+	_, ok := asArg[T](arg)
+	return ok
+}
+
+// Implements the [Matcher] interface.
+func (c *ArgCaptor[T]) String() string {
+	return fmt.Sprintf("Captor[%s]", reflect.TypeFor[T]())
+}
+
+func (c *ArgCaptor[T]) capture(arg any) {
+	v, _ := asArg[T](arg)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values = append(c.values, v)
+}
+
+// Returns a copy of all the captured arguments, in the order they were
+// captured.
+func (c *ArgCaptor[T]) Values() []T {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.values)
+}
+
+// Returns the most recently captured argument, or the zero value of T if no
+// argument has been captured.
+func (c *ArgCaptor[T]) Last() T {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var rv T
+	if len(c.values) > 0 {
+		rv = c.values[len(c.values)-1]
+	}
+	return rv
+}
+
+// Converts the supplied argument to a T, treating nil as the zero value when T
+// is an interface type.
+func asArg[T any](arg any) (T, bool) {
+	var rv T
+	if arg == nil {
+		return rv, reflect.TypeFor[T]().Kind() == reflect.Interface
+	}
+	rv, ok := arg.(T)
+	return rv, ok
+}
+
+// Returns true if the expected argument, which may be a [Matcher], matches
+// the actual argument.
+func argMatches(expected any, got any) bool {
+	if m, ok := expected.(Matcher); ok {
+		return m.Match(got)
+	}
+	return reflect.DeepEqual(expected, got)
+}
+
+// Formats an expected argument, which may be a [Matcher].
+func fmtArg(arg any) string {
+	if m, ok := arg.(Matcher); ok {
+		return m.String()
+	}
+	return fmt.Sprintf("%#v", arg)
+}
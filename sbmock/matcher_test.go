@@ -0,0 +1,38 @@
+package sbmock
+
+import (
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func TestMatchedBy(t *testing.T) {
+	nonEmpty := MatchedBy(func(k string) bool { return k != "" }, "non-empty key")
+	sbtest.True(t, nonEmpty.Match("a"))
+	sbtest.False(t, nonEmpty.Match(""))
+	sbtest.False(t, nonEmpty.Match(1))
+	sbtest.Eq(t, "MatchedBy(non-empty key)", nonEmpty.String())
+
+	noDesc := MatchedBy(func(k int) bool { return k > 0 })
+	sbtest.True(t, noDesc.Match(1))
+	sbtest.Eq(t, "MatchedBy(func(int) bool)", noDesc.String())
+}
+
+func TestAny(t *testing.T) {
+	sbtest.True(t, Any[int]().Match(1))
+	sbtest.False(t, Any[int]().Match("a"))
+	sbtest.True(t, Any[error]().Match(nil))
+	sbtest.False(t, Any[*int]().Match(nil))
+}
+
+func TestCaptor(t *testing.T) {
+	m := New(t)
+	c := Captor[string]()
+	m.On("Send", c, 1)
+	m.Called("Send", "a", 1)
+	m.Called("Send", "b", 2)
+	m.Called("Send", "c", 1)
+	sbtest.SlicesMatch(t, []string{"a", "c"}, c.Values())
+	sbtest.Eq(t, "c", c.Last())
+	sbtest.Eq(t, "", Captor[string]().Last())
+}
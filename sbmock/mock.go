@@ -0,0 +1,445 @@
+// Helpers for building mocks of interfaces whose expectations are verified
+// with the same failure format as the assertions in sbtest. A mock type
+// embeds a [*Mock] and forwards each of its methods to [Mock.Called]. Such
+// mock types, with typed methods for declaring expected calls, can be
+// generated with the sbtestgen command.
+package sbmock
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+type (
+	// Records the calls made to a mock and matches them against the expected
+	// calls that were declared with On. A mock of an interface embeds a Mock
+	// and implements each method of the interface by calling Called.
+	//
+	//	type MockStore struct {
+	//		*sbmock.Mock
+	//	}
+	//
+	//	func (m MockStore) Get(key string) (string, error) {
+	//		rets := m.Called("Get", key)
+	//		return sbmock.Ret[string](rets, 0), sbmock.Ret[error](rets, 1)
+	//	}
+	//
+	//	m := MockStore{sbmock.New(t)}
+	//	m.On("Get", "a").Return("1", nil)
+	//
+	// All methods are safe for concurrent use.
+	Mock struct {
+		t        testing.TB
+		mu       sync.Mutex
+		strict   bool
+		expected []*Call
+		calls    []Invocation
+	}
+
+	// An expected call to a method of a mock, declared with [Mock.On].
+	Call struct {
+		mock   *Mock
+		method string
+		args   []any
+		rets   []any
+		retFn  func(args []any) []any
+		// The number of times the call can be matched, zero if unlimited.
+		times    int
+		optional bool
+		// True once the call has been reported as unsatisfied by VerifyAll.
+		reported bool
+		file     string
+		line     int
+		// The sequence numbers of the invocations that matched the call.
+		matched []int
+	}
+
+	// Configures a mock when it is created with [New].
+	Option func(m *Mock)
+
+	// A single call that was made to a mock.
+	Invocation struct {
+		// The name of the method that was called.
+		Method string
+		// The arguments the method was called with.
+		Args []any
+		// The zero based position of the call among all the calls made to
+		// the mock.
+		Seq int
+	}
+)
+
+// Creates a new mock that reports to the supplied test, configured by the
+// supplied options. When the test completes the test fails if any of the
+// mock's expected calls were not satisfied, refer to [VerifyAll].
+//
+//	m := sbmock.New(t, sbmock.Strict())
+func New(t testing.TB, opts ...Option) *Mock {
+	m := &Mock{t: t}
+	for _, iterOpt := range opts {
+		iterOpt(m)
+	}
+	register(t, m)
+	return m
+}
+
+// Returns an option that puts the mock in strict mode, where a call that does
+// not match any expected call fails the test immediately. Outside of strict
+// mode such a call is recorded and returns no values, so [Ret] returns zero
+// values for it. The failure is reported at the method of the mock type that
+// was called, unless that method calls [sbtest.MarkHelper], in which case it
+// is reported at the code that called the mock.
+func Strict() Option {
+	return func(m *Mock) { m.strict = true }
+}
+
+// Declares that the named method is expected to be called with the supplied
+// arguments. Arguments are compared with [reflect.DeepEqual], unless the
+// expected argument is a [Matcher] such as [Any], [MatchedBy], or an
+// [ArgCaptor], in which case the matcher decides whether the argument matches.
+// When a call matches more than one expected call the one that was declared
+// first is used, skipping any that have already been matched as many times
+// as was allowed with [Call.Times]. This allows successive calls to return
+// different values:
+//
+//	m.On("Next").Return(1).Once()
+//	m.On("Next").Return(2).Once()
+//	m.On("Next").Return(0)
+func (m *Mock) On(method string, args ...any) *Call {
+	m.t.Helper()
+	f, line := sbtest.CallerLoc()
+	c := &Call{mock: m, method: method, args: args, file: f, line: line}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expected = append(m.expected, c)
+	return c
+}
+
+// Sets the values that are returned when the call is matched. Returns the
+// call so it can be chained with [Mock.On].
+func (c *Call) Return(vals ...any) *Call {
+	c.mock.mu.Lock()
+	defer c.mock.mu.Unlock()
+	c.rets = vals
+	c.retFn = nil
+	return c
+}
+
+// Sets a function that computes the values that are returned when the call is
+// matched from the arguments of the matching call. This replaces any values
+// set with [Call.Return], and vice versa. The function is called without the
+// mock's lock held, so it may call the mock. Returns the call so it can be
+// chained with [Mock.On].
+//
+//	m.On("Double", sbmock.Any[int]()).ReturnFunc(func(args []any) []any {
+//		return []any{sbmock.Ret[int](args, 0) * 2}
+//	})
+func (c *Call) ReturnFunc(fn func(args []any) []any) *Call {
+	c.mock.mu.Lock()
+	defer c.mock.mu.Unlock()
+	c.retFn = fn
+	c.rets = nil
+	return c
+}
+
+// Limits the call to being matched once. Equivalent to Times(1).
+func (c *Call) Once() *Call {
+	return c.Times(1)
+}
+
+// Limits the call to being matched n times. Once it has been matched n times
+// later calls are matched against the other expected calls instead. Use
+// [Consumed] to test that the call was matched all n times. Panics if n is
+// less than one.
+func (c *Call) Times(n int) *Call {
+	if n < 1 {
+		panic(fmt.Sprintf(
+			"sbmock: the number of times must be positive, got %d", n,
+		))
+	}
+	c.mock.mu.Lock()
+	defer c.mock.mu.Unlock()
+	c.times = n
+	return c
+}
+
+// Marks the call as optional, so [VerifyAll] does not report it when it is not
+// matched, or is matched fewer times than it was limited to.
+func (c *Call) Maybe() *Call {
+	c.mock.mu.Lock()
+	defer c.mock.mu.Unlock()
+	c.optional = true
+	return c
+}
+
+// Removes any limit on the number of times the call can be matched. This is
+// the default.
+func (c *Call) Repeatedly() *Call {
+	c.mock.mu.Lock()
+	defer c.mock.mu.Unlock()
+	c.times = 0
+	return c
+}
+
+// Records a call to the named method with the supplied arguments and returns
+// the values of the first expected call that matches it. This is intended to
+// be called by the methods of a mock type, refer to [Mock]. If no expected
+// call matches then nil is returned, and in strict mode the test fails.
+// Matchers are run without the mock's lock held, so they may call the mock.
+func (m *Mock) Called(method string, args ...any) []any {
+	m.t.Helper()
+	m.mu.Lock()
+	inv := Invocation{Method: method, Args: args, Seq: len(m.calls)}
+	m.calls = append(m.calls, inv)
+	expected := slices.Clone(m.expected)
+	m.mu.Unlock()
+
+	for _, iterCall := range expected {
+		if !iterCall.matches(inv) {
+			continue
+		}
+		m.mu.Lock()
+		if iterCall.exhausted() {
+			m.mu.Unlock()
+			continue
+		}
+		// Concurrent calls may get here out of order, but InOrder relies on
+		// the sequence numbers being sorted.
+		i, _ := slices.BinarySearch(iterCall.matched, inv.Seq)
+		iterCall.matched = slices.Insert(iterCall.matched, i, inv.Seq)
+		rets, retFn := iterCall.rets, iterCall.retFn
+		m.mu.Unlock()
+
+		for i, iterArg := range iterCall.args {
+			if c, ok := iterArg.(capturer); ok {
+				c.capture(inv.Args[i])
+			}
+		}
+		if retFn != nil {
+			return retFn(args)
+		}
+		return rets
+	}
+
+	m.mu.Lock()
+	strict := m.strict
+	fmtExpected := m.fmtExpected()
+	m.mu.Unlock()
+
+	if strict {
+		f, line := sbtest.CallerLoc()
+		sbtest.FormatError(
+			m.t, "an expected call", inv.String(),
+			"The mock received an unexpected call in strict mode."+
+				"\nExpected Calls:"+fmtExpected,
+			f, line,
+		)
+	}
+	return nil
+}
+
+// Returns a copy of all calls that have been made to the mock so far, in the
+// order they were made.
+func (m *Mock) Calls() []Invocation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Invocation{}, m.calls...)
+}
+
+// Returns the value at index i of the supplied return values as a T. The zero
+// value of T is returned if there is no value at the index or it is nil, so
+// mock methods can return the values of unexpected calls in non-strict mode.
+// Panics if the value is not a T, as that is a mistake in the declaration of
+// the expected call.
+func Ret[T any](rets []any, i int) T {
+	var rv T
+	if i >= len(rets) || rets[i] == nil {
+		return rv
+	}
+	rv, ok := rets[i].(T)
+	if !ok {
+		panic(fmt.Sprintf(
+			"sbmock: return value %d has type %T, expected %T", i, rets[i], rv,
+		))
+	}
+	return rv
+}
+
+// Tests that the supplied expected calls were matched in the supplied order.
+// Each expected call must have been matched by a call to the mock that was
+// made after a call that matched the previous expected call. Other calls may
+// be made in between. All the expected calls must be declared on the same
+// mock.
+//
+//	open := m.On("Open", "a.txt")
+//	closeCall := m.On("Close")
+//	...
+//	sbmock.InOrder(t, open, closeCall)
+func InOrder(t testing.TB, calls ...*Call) {
+	t.Helper()
+	f, line := sbtest.CallerLoc()
+	if len(calls) == 0 {
+		return
+	}
+	m := calls[0].mock
+	for _, iterCall := range calls[1:] {
+		if iterCall.mock != m {
+			sbtest.FormatError(
+				t, "calls of a single mock", "calls of several mocks",
+				"The expected calls passed to InOrder did not belong to the "+
+					"same mock.",
+				f, line,
+			)
+			return
+		}
+	}
+
+	m.mu.Lock()
+	failed, fmtCalls := -1, ""
+	prev := -1
+	for i, iterCall := range calls {
+		next := -1
+		for _, iterSeq := range iterCall.matched {
+			if iterSeq > prev {
+				next = iterSeq
+				break
+			}
+		}
+		if next < 0 {
+			failed, fmtCalls = i, fmtInvocations(m.calls)
+			break
+		}
+		prev = next
+	}
+	m.mu.Unlock()
+	if failed < 0 {
+		return
+	}
+	sbtest.FormatError(
+		t, calls[failed].String(), "no matching call after the previous one",
+		fmt.Sprintf(
+			"The expected call was not matched in order | Position: %d | "+
+				"Declared: File %s Line %d\nCalls:%s",
+			failed+1, calls[failed].file, calls[failed].line, fmtCalls,
+		),
+		f, line,
+	)
+}
+
+// Tests that every expected call of the supplied mock that was limited with
+// [Call.Once] or [Call.Times] was matched as many times as it was limited to,
+// so that no configured return value was left unused. This is usually called
+// at the end of a test.
+//
+//	defer sbmock.Consumed(t, m)
+func Consumed(t testing.TB, m *Mock) {
+	t.Helper()
+	m.mu.Lock()
+	var sb strings.Builder
+	cnt := 0
+	for _, iterCall := range m.expected {
+		if iterCall.times > 0 && !iterCall.exhausted() {
+			fmt.Fprintf(
+				&sb, "\n  %s | Matched: %d/%d | Declared: File %s Line %d",
+				iterCall, len(iterCall.matched), iterCall.times,
+				iterCall.file, iterCall.line,
+			)
+			cnt++
+		}
+	}
+	m.mu.Unlock()
+	if cnt > 0 {
+		f, line := sbtest.CallerLoc()
+		sbtest.FormatError(
+			t, 0, cnt,
+			"Limited expected calls were not matched as many times as allowed."+
+				"\nUnconsumed Calls:"+sb.String(),
+			f, line,
+		)
+	}
+}
+
+// Returns true if the call has been matched as many times as it is limited to.
+// The mock's lock must be held.
+func (c *Call) exhausted() bool {
+	return c.times > 0 && len(c.matched) >= c.times
+}
+
+// Returns true if the call has been matched as many times as it needs to be to
+// not be reported by [VerifyAll]. The mock's lock must be held.
+func (c *Call) satisfied() bool {
+	if c.optional {
+		return true
+	}
+	if c.times > 0 {
+		return c.exhausted()
+	}
+	return len(c.matched) > 0
+}
+
+// Returns true if the supplied invocation matches the call.
+func (c *Call) matches(inv Invocation) bool {
+	if c.method != inv.Method || len(c.args) != len(inv.Args) {
+		return false
+	}
+	for i, iterArg := range c.args {
+		if !argMatches(iterArg, inv.Args[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the call in the form Method(arg1, arg2).
+func (c *Call) String() string {
+	return fmtCall(c.method, c.args)
+}
+
+// Returns the invocation in the form Method(arg1, arg2).
+func (i Invocation) String() string {
+	return fmtCall(i.Method, i.Args)
+}
+
+// Formats all the expected calls of the mock. The mock's lock must be held.
+func (m *Mock) fmtExpected() string {
+	if len(m.expected) == 0 {
+		return " <no expected calls>"
+	}
+	var sb strings.Builder
+	for _, iterCall := range m.expected {
+		fmt.Fprintf(&sb, "\n  %s", iterCall)
+		if iterCall.times > 0 {
+			fmt.Fprintf(
+				&sb, " | Matched: %d/%d", len(iterCall.matched), iterCall.times,
+			)
+		}
+		fmt.Fprintf(
+			&sb, " | Declared: File %s Line %d", iterCall.file, iterCall.line,
+		)
+	}
+	return sb.String()
+}
+
+func fmtInvocations(calls []Invocation) string {
+	if len(calls) == 0 {
+		return " <no calls>"
+	}
+	var sb strings.Builder
+	for _, iterCall := range calls {
+		fmt.Fprintf(&sb, "\n  %d) %s", iterCall.Seq+1, iterCall)
+	}
+	return sb.String()
+}
+
+func fmtCall(method string, args []any) string {
+	strs := make([]string, len(args))
+	for i, iterArg := range args {
+		strs[i] = fmtArg(iterArg)
+	}
+	return fmt.Sprintf("%s(%s)", method, strings.Join(strs, ", "))
+}
@@ -0,0 +1,116 @@
+package sbmock
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+func TestStrictOption(t *testing.T) {
+	msgs := recordFailures(func(t testing.TB) {
+		m := New(t, Strict())
+		m.Called("Get", "a")
+	})
+	sbtest.Eq(t, 1, len(msgs))
+	sbtest.True(t, strings.Contains(msgs[0], "unexpected call in strict mode"))
+
+	m := New(t)
+	m.On("Get", "a").Maybe()
+	sbtest.Eq(t, 0, len(m.Called("Get", "b")))
+}
+
+func TestMatcherMayCallMock(t *testing.T) {
+	m := New(t)
+	m.On("Inner").Return(true)
+	m.On("Outer", MatchedBy(func(v int) bool {
+		return Ret[bool](m.Called("Inner"), 0)
+	})).Return("ok")
+
+	done := make(chan string, 1)
+	go func() { done <- Ret[string](m.Called("Outer", 1), 0) }()
+	select {
+	case v := <-done:
+		sbtest.Eq(t, "ok", v)
+	case <-time.After(5 * time.Second):
+		t.Fatal("calling the mock from a matcher deadlocked")
+	}
+}
+
+func TestConcurrentCallsStayOrdered(t *testing.T) {
+	m := New(t)
+	c := m.On("Inc", Any[int]())
+	var wg sync.WaitGroup
+	for i := range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Called("Inc", i)
+		}()
+	}
+	wg.Wait()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sbtest.Eq(t, 100, len(c.matched))
+	sbtest.NonDecreasing(t, c.matched)
+}
+
+func TestTimesAndReturnFunc(t *testing.T) {
+	m := New(t)
+	m.On("Next").Return(1).Once()
+	m.On("Next").Return(2).Times(2)
+	m.On("Double", Any[int]()).ReturnFunc(func(args []any) []any {
+		return []any{Ret[int](args, 0) * 2}
+	})
+	for _, iterExpected := range []int{1, 2, 2} {
+		sbtest.Eq(t, iterExpected, Ret[int](m.Called("Next"), 0))
+	}
+	sbtest.Eq(t, 0, len(m.Called("Next")))
+	sbtest.Eq(t, 6, Ret[int](m.Called("Double", 3), 0))
+	Consumed(t, m)
+}
+
+type callsOnFatalTB struct {
+	*recordingTB
+	m *Mock
+}
+
+func (c *callsOnFatalTB) Fatal(args ...any) {
+	c.m.Calls()
+	c.recordingTB.Fatal(args...)
+}
+
+func TestInOrder(t *testing.T) {
+	m := New(t)
+	open := m.On("Open")
+	closeCall := m.On("Close")
+	m.Called("Open")
+	m.Called("Read")
+	m.Called("Close")
+	InOrder(t, open, closeCall)
+
+	r := &recordingTB{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		InOrder(&callsOnFatalTB{recordingTB: r, m: m}, closeCall, open)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("reporting an InOrder failure deadlocked")
+	}
+	sbtest.Eq(t, 1, len(r.msgs))
+	sbtest.True(t, strings.Contains(r.msgs[0], "Position: 2"))
+}
+
+func TestPanicStackTrimsSubpackages(t *testing.T) {
+	msgs := recordFailures(func(t testing.TB) {
+		sbtest.NoPanic(t, func() { Ret[int]([]any{"a"}, 0) })
+	})
+	sbtest.Eq(t, 1, len(msgs))
+	sbtest.True(t, strings.Contains(msgs[0], "Stack:"))
+	sbtest.False(t, strings.Contains(msgs[0], "sbmock.Ret["))
+}
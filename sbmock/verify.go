@@ -0,0 +1,103 @@
+package sbmock
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+type (
+	// The mocks created for a single test.
+	mockSet struct {
+		mu    sync.Mutex
+		mocks []*Mock
+	}
+)
+
+// The mocks created for each test by [New].
+var mocks sync.Map
+
+// Registers the mock with the supplied test, verifying all the test's mocks
+// with [VerifyAll] when the test and all its subtests complete.
+func register(t testing.TB, m *Mock) {
+	set, loaded := mocks.LoadOrStore(t, &mockSet{})
+	s := set.(*mockSet)
+	s.mu.Lock()
+	s.mocks = append(s.mocks, m)
+	s.mu.Unlock()
+	if loaded {
+		return
+	}
+	t.Cleanup(func() {
+		t.Helper()
+		mocks.Delete(t)
+		if unmet := s.unmet(); len(unmet) > 0 {
+			reportUnmet(
+				t,
+				"Expected calls were not satisfied at the end of the test.",
+				unmet, unmet[0].file, unmet[0].line,
+			)
+		}
+	})
+}
+
+// Tests that every expected call declared on the mocks created for the
+// supplied test with [New] was satisfied. An expected call is satisfied once it
+// has been matched, or matched n times if it was limited with [Call.Times],
+// unless it was marked as optional with [Call.Maybe]. The test fails listing
+// every unsatisfied call along with the file and line it was declared at.
+//
+// This is called automatically when the test completes, so it only needs to be
+// called to verify the mocks before the end of the test. Each unsatisfied call
+// is only reported once, so it is not reported again at the end of the test.
+func VerifyAll(t testing.TB) {
+	t.Helper()
+	f, line := sbtest.CallerLoc()
+	set, ok := mocks.Load(t)
+	if !ok {
+		return
+	}
+	if unmet := set.(*mockSet).unmet(); len(unmet) > 0 {
+		reportUnmet(t, "Expected calls were not satisfied.", unmet, f, line)
+	}
+}
+
+// Returns the expected calls of every mock in the set that are not satisfied
+// and have not already been reported, marking them as reported.
+func (s *mockSet) unmet() []*Call {
+	s.mu.Lock()
+	ms := append([]*Mock{}, s.mocks...)
+	s.mu.Unlock()
+	rv := []*Call{}
+	for _, iterMock := range ms {
+		iterMock.mu.Lock()
+		for _, iterCall := range iterMock.expected {
+			if !iterCall.reported && !iterCall.satisfied() {
+				iterCall.reported = true
+				rv = append(rv, iterCall)
+			}
+		}
+		iterMock.mu.Unlock()
+	}
+	return rv
+}
+
+func reportUnmet(t testing.TB, msg string, unmet []*Call, f string, line int) {
+	t.Helper()
+	var sb strings.Builder
+	for _, iterCall := range unmet {
+		fmt.Fprintf(&sb, "\n  %s | Matched: %d", iterCall, len(iterCall.matched))
+		if iterCall.times > 0 {
+			fmt.Fprintf(&sb, "/%d", iterCall.times)
+		}
+		fmt.Fprintf(
+			&sb, " | Declared: File %s Line %d", iterCall.file, iterCall.line,
+		)
+	}
+	sbtest.FormatError(
+		t, 0, len(unmet), msg+"\nUnsatisfied Calls:"+sb.String(), f, line,
+	)
+}
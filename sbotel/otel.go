@@ -0,0 +1,136 @@
+// Helpers for testing OpenTelemetry tracing instrumentation by recording the
+// spans a test produces and asserting on their names, attributes, and
+// parents.
+package sbotel
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// Installs a tracer provider that exports every span to an in memory exporter
+// as soon as the span ends and returns the exporter. The provider is set as
+// the global tracer provider so that instrumentation which uses [otel.Tracer]
+// is captured. The previous global tracer provider is restored and the
+// provider is shut down when the test completes. Because the global provider
+// is replaced, tests that call StartTracing must not run in parallel.
+//
+//	exp := sbotel.StartTracing(t)
+//	handler.ServeHTTP(w, req)
+//	sbotel.SpanRecorded(t, exp, "GET /users", attribute.Int("http.status_code", 200))
+func StartTracing(t testing.TB) *tracetest.InMemoryExporter {
+	t.Helper()
+	exp := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prev)
+		provider.Shutdown(context.Background())
+	})
+	return exp
+}
+
+// Tests that the exporter contains at least one span with the supplied name
+// that has all of the supplied attributes. Attributes on the span that are not
+// supplied are ignored. The matching span is returned so that further
+// assertions can be made on it. All exported spans are listed on failure.
+func SpanRecorded(
+	t testing.TB,
+	exp *tracetest.InMemoryExporter,
+	name string,
+	attrs ...attribute.KeyValue,
+) tracetest.SpanStub {
+	t.Helper()
+	spans := exp.GetSpans()
+	for _, iterSpan := range spans {
+		if iterSpan.Name == name && hasAttrs(iterSpan, attrs) {
+			return iterSpan
+		}
+	}
+	f, line := sbtest.CallerLoc()
+	sbtest.FormatError(
+		t, fmt.Sprintf("%s %s", name, fmtAttrs(attrs)), nil,
+		"No span was found with the expected name and attributes.\nSpans:"+
+			fmtSpans(spans),
+		f, line,
+	)
+	return tracetest.SpanStub{}
+}
+
+// Tests that the exporter contains a span with the supplied parent name and a
+// span with the supplied child name whose parent is that span. If several
+// spans share a name any pairing satisfies the assertion. All exported spans
+// are listed on failure.
+func SpanParentOf(
+	t testing.TB,
+	exp *tracetest.InMemoryExporter,
+	parentName string,
+	childName string,
+) {
+	t.Helper()
+	spans := exp.GetSpans()
+	for _, iterParent := range spans {
+		if iterParent.Name != parentName {
+			continue
+		}
+		for _, iterChild := range spans {
+			if iterChild.Name == childName &&
+				iterChild.Parent.IsValid() &&
+				iterChild.Parent.TraceID() == iterParent.SpanContext.TraceID() &&
+				iterChild.Parent.SpanID() == iterParent.SpanContext.SpanID() {
+				return
+			}
+		}
+	}
+	f, line := sbtest.CallerLoc()
+	sbtest.FormatError(
+		t, parentName, nil,
+		fmt.Sprintf(
+			"No span was found that is the parent of the child span | Child: %s\nSpans:%s",
+			childName, fmtSpans(spans),
+		),
+		f, line,
+	)
+}
+
+func hasAttrs(span tracetest.SpanStub, attrs []attribute.KeyValue) bool {
+	for _, iterAttr := range attrs {
+		if !slices.Contains(span.Attributes, iterAttr) {
+			return false
+		}
+	}
+	return true
+}
+
+func fmtAttrs(attrs []attribute.KeyValue) string {
+	pairs := make([]string, len(attrs))
+	for i, iterAttr := range attrs {
+		pairs[i] = fmt.Sprintf("%s=%s", iterAttr.Key, iterAttr.Value.Emit())
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+func fmtSpans(spans tracetest.SpanStubs) string {
+	if len(spans) == 0 {
+		return " <no spans>"
+	}
+	var sb strings.Builder
+	for i, iterSpan := range spans {
+		fmt.Fprintf(
+			&sb, "\n  %d: %s | Span: %s | Parent: %s | Attrs: %s",
+			i, iterSpan.Name, iterSpan.SpanContext.SpanID(),
+			iterSpan.Parent.SpanID(), fmtAttrs(iterSpan.Attributes),
+		)
+	}
+	return sb.String()
+}
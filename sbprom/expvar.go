@@ -0,0 +1,83 @@
+package sbprom
+
+import (
+	"expvar"
+	"fmt"
+	"strconv"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+// Tests that the published [expvar.Var] with the supplied name has the
+// expected numeric value. Any var whose String method returns a number, such
+// as [expvar.Int] and [expvar.Float], can be used.
+func ExpvarEq(t testing.TB, name string, expected float64) {
+	t.Helper()
+	f, line := sbtest.CallerLoc()
+	got, ok := expvarValue(t, name, false, f, line)
+	if ok && got != expected {
+		sbtest.FormatError(
+			t, expected, got,
+			fmt.Sprintf("The expvar did not have the expected value | Name: %s", name),
+			f, line,
+		)
+	}
+}
+
+// Tests that the numeric value of the published [expvar.Var] with the supplied
+// name changes by exactly the expected delta while fn runs. A var that is not
+// published until fn is called is treated as having a value of zero before fn
+// is called.
+func ExpvarDelta(t testing.TB, name string, expectedDelta float64, fn func()) {
+	t.Helper()
+	f, line := sbtest.CallerLoc()
+	before, ok := expvarValue(t, name, true, f, line)
+	if !ok {
+		return
+	}
+	fn()
+	after, ok := expvarValue(t, name, false, f, line)
+	if ok && after-before != expectedDelta {
+		sbtest.FormatError(
+			t, expectedDelta, after-before,
+			fmt.Sprintf(
+				"The expvar did not change by the expected amount | Name: %s | Before: %v | After: %v",
+				name, before, after,
+			),
+			f, line,
+		)
+	}
+}
+
+func expvarValue(
+	t testing.TB,
+	name string,
+	allowMissing bool,
+	f string,
+	line int,
+) (float64, bool) {
+	t.Helper()
+	v := expvar.Get(name)
+	if v == nil {
+		if allowMissing {
+			return 0, true
+		}
+		sbtest.FormatError(
+			t, name, nil,
+			"The expvar was not published.",
+			f, line,
+		)
+		return 0, false
+	}
+	rv, err := strconv.ParseFloat(v.String(), 64)
+	if err != nil {
+		sbtest.FormatError(
+			t, "a numeric value", v.String(),
+			fmt.Sprintf("The expvar did not have a numeric value | Name: %s", name),
+			f, line,
+		)
+		return 0, false
+	}
+	return rv, true
+}
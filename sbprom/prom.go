@@ -0,0 +1,183 @@
+// Assertions on the values of Prometheus metrics and expvar variables, read
+// directly from their registries instead of by scraping an HTTP endpoint.
+// Importing this package registers the /debug/vars handler of expvar with the
+// default HTTP mux.
+package sbprom
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Tests that the metric with the supplied name and labels that is gathered
+// from the supplied gatherer has the expected value. The labels must match the
+// full label set of a single metric, a nil map matches a metric with no
+// labels. Counters, gauges, and untyped metrics are compared using their
+// value, histograms and summaries are compared using their sample count. All
+// label sets of the metric are listed on failure.
+//
+//	sbprom.MetricEq(
+//		t, prometheus.DefaultGatherer,
+//		"requests_total", map[string]string{"code": "200"},
+//		1,
+//	)
+func MetricEq(
+	t testing.TB,
+	g prometheus.Gatherer,
+	name string,
+	labels map[string]string,
+	expected float64,
+) {
+	t.Helper()
+	f, line := sbtest.CallerLoc()
+	got, ok := gatherValue(t, g, name, labels, false, f, line)
+	if ok && got != expected {
+		sbtest.FormatError(
+			t, expected, got,
+			fmt.Sprintf(
+				"The metric did not have the expected value | Name: %s | Labels: %s",
+				name, fmtLabels(labels),
+			),
+			f, line,
+		)
+	}
+}
+
+// Tests that the value of the metric with the supplied name and labels changes
+// by exactly the expected delta while fn runs. The metric is gathered before
+// and after fn is called, a metric that does not exist yet before fn is called
+// is treated as having a value of zero. Refer to [MetricEq] for how metrics
+// are matched and compared.
+//
+//	sbprom.MetricDelta(
+//		t, prometheus.DefaultGatherer,
+//		"requests_total", map[string]string{"code": "200"},
+//		1,
+//		func() { client.Get(url) },
+//	)
+func MetricDelta(
+	t testing.TB,
+	g prometheus.Gatherer,
+	name string,
+	labels map[string]string,
+	expectedDelta float64,
+	fn func(),
+) {
+	t.Helper()
+	f, line := sbtest.CallerLoc()
+	before, ok := gatherValue(t, g, name, labels, true, f, line)
+	if !ok {
+		return
+	}
+	fn()
+	after, ok := gatherValue(t, g, name, labels, false, f, line)
+	if ok && after-before != expectedDelta {
+		sbtest.FormatError(
+			t, expectedDelta, after-before,
+			fmt.Sprintf(
+				"The metric did not change by the expected amount | Name: %s | Labels: %s | Before: %v | After: %v",
+				name, fmtLabels(labels), before, after,
+			),
+			f, line,
+		)
+	}
+}
+
+// Gathers the value of the metric with the supplied name and labels. If
+// allowMissing is true a missing metric has a value of zero, otherwise it fails
+// the test.
+func gatherValue(
+	t testing.TB,
+	g prometheus.Gatherer,
+	name string,
+	labels map[string]string,
+	allowMissing bool,
+	f string,
+	line int,
+) (float64, bool) {
+	t.Helper()
+	families, err := g.Gather()
+	if err != nil {
+		sbtest.FormatError(
+			t, nil, err,
+			"The metrics could not be gathered.",
+			f, line,
+		)
+		return 0, false
+	}
+
+	var family *dto.MetricFamily
+	for _, iterFamily := range families {
+		if iterFamily.GetName() == name {
+			family = iterFamily
+			break
+		}
+	}
+	labelSets := []string{}
+	if family != nil {
+		for _, iterMetric := range family.GetMetric() {
+			got := map[string]string{}
+			for _, iterPair := range iterMetric.GetLabel() {
+				got[iterPair.GetName()] = iterPair.GetValue()
+			}
+			if maps.Equal(got, labels) {
+				return metricValue(iterMetric), true
+			}
+			labelSets = append(labelSets, fmtLabels(got))
+		}
+	}
+	if allowMissing {
+		return 0, true
+	}
+
+	var sb strings.Builder
+	if len(labelSets) == 0 {
+		sb.WriteString(" <no metrics>")
+	}
+	for _, iterSet := range labelSets {
+		sb.WriteString("\n  " + iterSet)
+	}
+	sbtest.FormatError(
+		t, fmtLabels(labels), nil,
+		fmt.Sprintf(
+			"The metric was not found | Name: %s\nLabel Sets:%s",
+			name, sb.String(),
+		),
+		f, line,
+	)
+	return 0, false
+}
+
+func metricValue(m *dto.Metric) float64 {
+	switch {
+	case m.Counter != nil:
+		return m.GetCounter().GetValue()
+	case m.Gauge != nil:
+		return m.GetGauge().GetValue()
+	case m.Untyped != nil:
+		return m.GetUntyped().GetValue()
+	case m.Histogram != nil:
+		return float64(m.GetHistogram().GetSampleCount())
+	case m.Summary != nil:
+		return float64(m.GetSummary().GetSampleCount())
+	default:
+		return math.NaN()
+	}
+}
+
+// Returns the labels in the same form as the Prometheus text exposition format.
+func fmtLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for _, k := range slices.Sorted(maps.Keys(labels)) {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", k, labels[k]))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
@@ -0,0 +1,269 @@
+// Assertions for protobuf messages, which are compared with [proto.Equal]
+// rather than by their Go representation.
+package sbproto
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Tests that the supplied messages are equal according to [proto.Equal]. Unlike
+// [sbtest.Eq] and [reflect.DeepEqual] this follows protobuf semantics: unknown
+// fields are compared, fields without presence that are set to their zero
+// value are equal to unset fields, and maps are compared regardless of their
+// iteration order. On failure a diff of the fields that differ is shown, with
+// each field identified by its path from the root message. Fields only present
+// in expected are prefixed with '-' and fields only present in got are prefixed
+// with '+'.
+func EqProto(t testing.TB, expected proto.Message, got proto.Message) {
+	t.Helper()
+	if proto.Equal(expected, got) {
+		return
+	}
+	f, line := sbtest.CallerLoc()
+	sbtest.FormatError(
+		t, fmtMessage(expected), fmtMessage(got),
+		fmt.Sprintf(
+			"The supplied messages were not equal but were expected to be.\nDiff:%s",
+			diff(expected, got),
+		),
+		f, line,
+	)
+}
+
+// Returns a diff of the fields that differ between the supplied messages.
+func diff(expected proto.Message, got proto.Message) string {
+	var e, g protoreflect.Message
+	if expected != nil {
+		e = expected.ProtoReflect()
+	}
+	if got != nil {
+		g = got.ProtoReflect()
+	}
+	if e == nil || g == nil || !e.IsValid() || !g.IsValid() {
+		return fmt.Sprintf("\n- %s\n+ %s", fmtMessage(expected), fmtMessage(got))
+	}
+	if e.Descriptor().FullName() != g.Descriptor().FullName() {
+		return fmt.Sprintf(
+			"\n- <%s>\n+ <%s>",
+			e.Descriptor().FullName(), g.Descriptor().FullName(),
+		)
+	}
+
+	var sb strings.Builder
+	diffMessage(&sb, "", e, g)
+	return sb.String()
+}
+
+func diffMessage(
+	sb *strings.Builder,
+	path string,
+	e protoreflect.Message,
+	g protoreflect.Message,
+) {
+	for _, iterFd := range populatedFields(e, g) {
+		fieldPath := joinPath(path, iterFd)
+		if !e.Has(iterFd) {
+			writeLine(sb, "+", fieldPath, fmtValue(iterFd, g.Get(iterFd)))
+			continue
+		}
+		if !g.Has(iterFd) {
+			writeLine(sb, "-", fieldPath, fmtValue(iterFd, e.Get(iterFd)))
+			continue
+		}
+		switch {
+		case iterFd.IsList():
+			diffList(sb, fieldPath, iterFd, e.Get(iterFd).List(), g.Get(iterFd).List())
+		case iterFd.IsMap():
+			diffMap(sb, fieldPath, iterFd, e.Get(iterFd).Map(), g.Get(iterFd).Map())
+		default:
+			diffSingular(sb, fieldPath, iterFd, e.Get(iterFd), g.Get(iterFd))
+		}
+	}
+
+	if eu, gu := e.GetUnknown(), g.GetUnknown(); !bytes.Equal(eu, gu) {
+		unknownPath := path + "<unknown fields>"
+		if path != "" {
+			unknownPath = path + ".<unknown fields>"
+		}
+		writeLine(sb, "-", unknownPath, fmt.Sprintf("%x", []byte(eu)))
+		writeLine(sb, "+", unknownPath, fmt.Sprintf("%x", []byte(gu)))
+	}
+}
+
+func diffList(
+	sb *strings.Builder,
+	path string,
+	fd protoreflect.FieldDescriptor,
+	e protoreflect.List,
+	g protoreflect.List,
+) {
+	for i := range max(e.Len(), g.Len()) {
+		elemPath := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i >= e.Len():
+			writeLine(sb, "+", elemPath, fmtSingular(fd, g.Get(i)))
+		case i >= g.Len():
+			writeLine(sb, "-", elemPath, fmtSingular(fd, e.Get(i)))
+		default:
+			diffSingular(sb, elemPath, fd, e.Get(i), g.Get(i))
+		}
+	}
+}
+
+func diffMap(
+	sb *strings.Builder,
+	path string,
+	fd protoreflect.FieldDescriptor,
+	e protoreflect.Map,
+	g protoreflect.Map,
+) {
+	keys := []protoreflect.MapKey{}
+	e.Range(func(k protoreflect.MapKey, _ protoreflect.Value) bool {
+		keys = append(keys, k)
+		return true
+	})
+	g.Range(func(k protoreflect.MapKey, _ protoreflect.Value) bool {
+		if !e.Has(k) {
+			keys = append(keys, k)
+		}
+		return true
+	})
+	slices.SortFunc(keys, func(a, b protoreflect.MapKey) int {
+		return cmp.Compare(fmtMapKey(a), fmtMapKey(b))
+	})
+
+	valFd := fd.MapValue()
+	for _, iterKey := range keys {
+		entryPath := fmt.Sprintf("%s[%s]", path, fmtMapKey(iterKey))
+		switch {
+		case !e.Has(iterKey):
+			writeLine(sb, "+", entryPath, fmtSingular(valFd, g.Get(iterKey)))
+		case !g.Has(iterKey):
+			writeLine(sb, "-", entryPath, fmtSingular(valFd, e.Get(iterKey)))
+		default:
+			diffSingular(sb, entryPath, valFd, e.Get(iterKey), g.Get(iterKey))
+		}
+	}
+}
+
+func diffSingular(
+	sb *strings.Builder,
+	path string,
+	fd protoreflect.FieldDescriptor,
+	e protoreflect.Value,
+	g protoreflect.Value,
+) {
+	if fd.Message() != nil {
+		diffMessage(sb, path, e.Message(), g.Message())
+		return
+	}
+	if e.Equal(g) {
+		return
+	}
+	writeLine(sb, "-", path, fmtSingular(fd, e))
+	writeLine(sb, "+", path, fmtSingular(fd, g))
+}
+
+// Returns the fields, including extensions, that are populated in either of
+// the supplied messages sorted by field number.
+func populatedFields(
+	e protoreflect.Message,
+	g protoreflect.Message,
+) []protoreflect.FieldDescriptor {
+	rv := []protoreflect.FieldDescriptor{}
+	e.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		rv = append(rv, fd)
+		return true
+	})
+	g.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if !e.Has(fd) {
+			rv = append(rv, fd)
+		}
+		return true
+	})
+	slices.SortFunc(rv, func(a, b protoreflect.FieldDescriptor) int {
+		return cmp.Compare(a.Number(), b.Number())
+	})
+	return rv
+}
+
+func joinPath(path string, fd protoreflect.FieldDescriptor) string {
+	name := fd.TextName()
+	if fd.IsExtension() {
+		name = "[" + string(fd.FullName()) + "]"
+	}
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func writeLine(sb *strings.Builder, prefix string, path string, val string) {
+	fmt.Fprintf(sb, "\n%s %s: %s", prefix, path, val)
+}
+
+func fmtValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	switch {
+	case fd.IsList():
+		l := v.List()
+		elems := make([]string, l.Len())
+		for i := range l.Len() {
+			elems[i] = fmtSingular(fd, l.Get(i))
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	case fd.IsMap():
+		entries := []string{}
+		v.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+			entries = append(
+				entries, fmtMapKey(k)+": "+fmtSingular(fd.MapValue(), v),
+			)
+			return true
+		})
+		slices.Sort(entries)
+		return "{" + strings.Join(entries, ", ") + "}"
+	default:
+		return fmtSingular(fd, v)
+	}
+}
+
+func fmtSingular(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return "{" + fmtMessage(v.Message().Interface()) + "}"
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return fmt.Sprintf("%d", v.Enum())
+	case protoreflect.StringKind:
+		return fmt.Sprintf("%q", v.String())
+	case protoreflect.BytesKind:
+		return fmt.Sprintf("%q", v.Bytes())
+	default:
+		return fmt.Sprintf("%v", v.Interface())
+	}
+}
+
+func fmtMapKey(k protoreflect.MapKey) string {
+	if s, ok := k.Interface().(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprintf("%v", k.Interface())
+}
+
+func fmtMessage(m proto.Message) string {
+	if m == nil || !m.ProtoReflect().IsValid() {
+		return "<nil>"
+	}
+	return prototext.MarshalOptions{}.Format(m)
+}
@@ -0,0 +1,445 @@
+// Generates strongly typed mocks of interfaces that are built on sbmock. Each
+// generated mock embeds a *sbmock.Mock, so it supports the same expectations,
+// matchers, and verification, and adds typed methods for declaring expected
+// calls so mistakes in argument and return types are caught by the compiler
+// rather than at run time.
+//
+// Run it with go generate from the package that declares the interfaces:
+//
+//	//go:generate go run github.com/barbell-math/smoothbrain-test/sbtestgen -type Store
+//
+// For an interface Store with a method Get(key string) (string, error) this
+// generates a MockStore type, a NewMockStore(t, opts...) constructor that
+// takes the same options as sbmock.New, and an OnGet method that returns a
+// call whose Return method takes a string and an error and whose ReturnFunc
+// method takes a function with the signature of Get:
+//
+//	m := NewMockStore(t, sbmock.Strict())
+//	m.OnGet("a").Return("1", nil).Once()
+//	m.OnGet(sbmock.Any[string]()).ReturnFunc(func(key string) (string, error) {
+//		return "", fmt.Errorf("%s not found", key)
+//	})
+//
+// The arguments of the On methods are of type any so that matchers such as
+// sbmock.Any can be passed in place of any argument. Interfaces with a method
+// named Mock, or with both a method X and a method OnX, cannot be mocked as
+// the generated methods would clash. The flags are:
+//
+//	-type  comma separated names of the interfaces to mock (required)
+//	-out   the file to write (default mock_<type>_test.go)
+//	-pkg   the package of the generated file (default the interfaces' package)
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+type (
+	// The state of a single run of the generator.
+	generator struct {
+		pkg     *types.Package
+		outPkg  string
+		imports map[string]string
+		buf     bytes.Buffer
+	}
+
+	// A parameter of a mocked method.
+	param struct {
+		name     string
+		typ      string
+		variadic bool
+		// The typed nil that a nil argument of an On method is converted to,
+		// empty if the type cannot be nil or is an interface.
+		typedNil string
+	}
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma separated names of the interfaces to mock")
+	out := flag.String("out", "", "the file to write")
+	outPkg := flag.String("pkg", "", "the package of the generated file")
+	flag.Parse()
+	if *typeNames == "" {
+		fmt.Fprintln(os.Stderr, "sbtestgen: the -type flag is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+	names := strings.Split(*typeNames, ",")
+	if *out == "" {
+		*out = fmt.Sprintf("mock_%s_test.go", strings.ToLower(names[0]))
+	}
+	if err := run(names, *out, *outPkg); err != nil {
+		fmt.Fprintf(os.Stderr, "sbtestgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(names []string, out string, outPkg string) error {
+	pkg, err := loadPackage(".", out, outPkg)
+	if err != nil {
+		return err
+	}
+	g := &generator{pkg: pkg, outPkg: outPkg, imports: map[string]string{}}
+	if g.outPkg == "" {
+		g.outPkg = pkg.Name()
+	}
+	for _, iterName := range names {
+		if err := g.genMock(strings.TrimSpace(iterName)); err != nil {
+			return err
+		}
+	}
+	src, err := g.file()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(out, src, 0o644)
+}
+
+// Parses and type checks the non-test files of the package in the supplied
+// directory, skipping the output file so a stale mock cannot break the build.
+func loadPackage(dir string, out string, outPkg string) (*types.Package, error) {
+	fset := token.NewFileSet()
+	matches, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	files := []*ast.File{}
+	for _, iterPath := range matches {
+		if strings.HasSuffix(iterPath, "_test.go") ||
+			filepath.Base(iterPath) == filepath.Base(out) {
+			continue
+		}
+		file, err := parser.ParseFile(fset, iterPath, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no Go files found in %s", dir)
+	}
+	path := files[0].Name.Name
+	if outPkg != "" && outPkg != path {
+		// The generated file is in another package, such as an external test
+		// package, so types from this package need to be imported.
+		rv, err := exec.Command("go", "list", "-f", "{{.ImportPath}}", dir).Output()
+		if err != nil {
+			return nil, fmt.Errorf("could not find the import path of %s: %w", dir, err)
+		}
+		path = strings.TrimSpace(string(rv))
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	return conf.Check(path, fset, files, nil)
+}
+
+func (g *generator) genMock(name string) error {
+	obj := g.pkg.Scope().Lookup(name)
+	if obj == nil {
+		return fmt.Errorf("type %s was not found in package %s", name, g.pkg.Name())
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok || named.TypeParams().Len() > 0 {
+		return fmt.Errorf("type %s is not a non-generic named type", name)
+	}
+	iface, ok := named.Underlying().(*types.Interface)
+	if !ok {
+		return fmt.Errorf("type %s is not an interface", name)
+	}
+	for i := range iface.NumMethods() {
+		method := iface.Method(i).Name()
+		if method == "Mock" {
+			return fmt.Errorf(
+				"type %s has a method named Mock, which clashes with the "+
+					"embedded *sbmock.Mock of the generated mock", name,
+			)
+		}
+		if declared := strings.TrimPrefix(method, "On"); declared != method &&
+			hasMethod(iface, declared) {
+			return fmt.Errorf(
+				"type %s has a method named %s, which clashes with the "+
+					"generated method that declares calls to %s",
+				name, method, declared,
+			)
+		}
+	}
+	ifaceRef := g.qualifiedName(obj)
+	mock := "Mock" + name
+	g.imports["testing"] = "testing"
+	g.imports["github.com/barbell-math/smoothbrain-test"] = "sbtest"
+	g.imports["github.com/barbell-math/smoothbrain-test/sbmock"] = "sbmock"
+
+	g.printf(`
+// A mock of [%[2]s] generated by sbtestgen.
+type %[1]s struct {
+	*sbmock.Mock
+}
+
+var _ %[2]s = (*%[1]s)(nil)
+
+// Creates a new mock of [%[2]s] that reports to the supplied test, refer to
+// [sbmock.New].
+func New%[1]s(t testing.TB, opts ...sbmock.Option) *%[1]s {
+	return &%[1]s{Mock: sbmock.New(t, opts...)}
+}
+`, mock, ifaceRef)
+
+	for i := range iface.NumMethods() {
+		g.genMethod(mock, ifaceRef, iface.Method(i))
+	}
+	return nil
+}
+
+func (g *generator) genMethod(mock string, ifaceRef string, method *types.Func) {
+	sig := method.Type().(*types.Signature)
+	name := method.Name()
+	call := mock + name + "Call"
+	results := []string{}
+	for i := range sig.Results().Len() {
+		results = append(results, g.typeString(sig.Results().At(i).Type()))
+	}
+	params := g.params(sig)
+
+	sigParams, args, anyParams := []string{}, []string{}, []string{}
+	var typedNils strings.Builder
+	for _, iterParam := range params {
+		typ := iterParam.typ
+		if iterParam.variadic {
+			typ = "..." + strings.TrimPrefix(typ, "[]")
+		}
+		sigParams = append(sigParams, iterParam.name+" "+typ)
+		args = append(args, iterParam.name)
+		anyParams = append(anyParams, iterParam.name+" any")
+		if iterParam.typedNil != "" {
+			// An untyped nil would not be equal to the typed nil the mocked
+			// method passes to Called.
+			fmt.Fprintf(
+				&typedNils, "\tif %[1]s == nil {\n\t\t%[1]s = %[2]s\n\t}\n",
+				iterParam.name, iterParam.typedNil,
+			)
+		}
+	}
+	resultList := strings.Join(results, ", ")
+	if len(results) > 1 {
+		resultList = "(" + resultList + ")"
+	}
+
+	g.printf(`
+// Implements %[2]s of [%[1]s] by recording the call, refer to
+// [sbmock.Mock.Called].
+func (m *%[3]s) %[2]s(%[4]s) %[5]s {
+	sbtest.MarkHelper()
+`, ifaceRef, name, mock, strings.Join(sigParams, ", "), resultList)
+	calledArgs := append([]string{fmt.Sprintf("%q", name)}, args...)
+	if len(results) == 0 {
+		g.printf("\tm.Mock.Called(%s)\n}\n", strings.Join(calledArgs, ", "))
+	} else {
+		rets := make([]string, len(results))
+		for i, iterResult := range results {
+			rets[i] = fmt.Sprintf("sbmock.Ret[%s](rets, %d)", iterResult, i)
+		}
+		g.printf(
+			"\trets := m.Mock.Called(%s)\n\treturn %s\n}\n",
+			strings.Join(calledArgs, ", "), strings.Join(rets, ", "),
+		)
+	}
+
+	g.printf(`
+// An expected call to [%[1]s.%[2]s].
+type %[3]s struct {
+	*sbmock.Call
+}
+
+// Declares that %[2]s is expected to be called with the supplied arguments,
+// each of which may be a [sbmock.Matcher]. A nil argument matches a nil value
+// of the parameter's type and a variadic argument is matched as a slice. Refer
+// to [sbmock.Mock.On].
+func (m *%[1]s) On%[2]s(%[4]s) *%[3]s {
+	sbtest.MarkHelper()
+%[6]s	return &%[3]s{Call: m.Mock.On(%[5]s)}
+}
+`,
+		mock, name, call, strings.Join(anyParams, ", "),
+		strings.Join(calledArgs, ", "), typedNils.String(),
+	)
+	for _, iterLimit := range []struct{ name, params, args string }{
+		{"Once", "", ""},
+		{"Times", "n int", "n"},
+		{"Repeatedly", "", ""},
+		{"Maybe", "", ""},
+	} {
+		g.printf(`
+// Refer to [sbmock.Call.%[2]s].
+func (c *%[1]s) %[2]s(%[3]s) *%[1]s {
+	c.Call.%[2]s(%[4]s)
+	return c
+}
+`, call, iterLimit.name, iterLimit.params, iterLimit.args)
+	}
+	if len(results) == 0 {
+		return
+	}
+
+	retParams, retArgs := []string{}, []string{}
+	for i, iterResult := range results {
+		retParams = append(retParams, fmt.Sprintf("r%d %s", i, iterResult))
+		retArgs = append(retArgs, fmt.Sprintf("r%d", i))
+	}
+	g.printf(`
+// Sets the values that are returned when the call is matched.
+func (c *%[1]s) Return(%[2]s) *%[1]s {
+	c.Call.Return(%[3]s)
+	return c
+}
+`, call, strings.Join(retParams, ", "), strings.Join(retArgs, ", "))
+
+	fnArgs := []string{}
+	for i, iterParam := range params {
+		arg := fmt.Sprintf("sbmock.Ret[%s](args, %d)", iterParam.typ, i)
+		if iterParam.variadic {
+			arg += "..."
+		}
+		fnArgs = append(fnArgs, arg)
+	}
+	g.printf(`
+// Sets a function that computes the values that are returned when the call is
+// matched from the arguments of the matching call. Refer to
+// [sbmock.Call.ReturnFunc].
+func (c *%[1]s) ReturnFunc(fn func(%[2]s) %[3]s) *%[1]s {
+	c.Call.ReturnFunc(func(args []any) []any {
+		%[4]s := fn(%[5]s)
+		return []any{%[4]s}
+	})
+	return c
+}
+`,
+		call, strings.Join(sigParams, ", "), resultList,
+		strings.Join(retArgs, ", "), strings.Join(fnArgs, ", "),
+	)
+}
+
+// Returns the parameters of the supplied signature, renaming any that are
+// unnamed or that would clash with the names used by the generated code. The
+// names of the imported packages and the predeclared identifiers are reserved
+// as a parameter with the same name would shadow them in the body of the
+// generated methods, so the types of all the parameters and results must be
+// registered before calling this.
+func (g *generator) params(sig *types.Signature) []param {
+	rv := []param{}
+	typs := make([]string, sig.Params().Len())
+	for i := range sig.Params().Len() {
+		typs[i] = g.typeString(sig.Params().At(i).Type())
+	}
+	used := map[string]bool{"m": true, "c": true, "rets": true}
+	for _, iterName := range g.imports {
+		used[iterName] = true
+	}
+	for i := range sig.Params().Len() {
+		v := sig.Params().At(i)
+		name := v.Name()
+		for j := 0; name == "" || name == "_" || used[name] ||
+			types.Universe.Lookup(name) != nil; j++ {
+			name = fmt.Sprintf("a%d", i+j*sig.Params().Len())
+		}
+		used[name] = true
+		p := param{
+			name:     name,
+			typ:      typs[i],
+			variadic: sig.Variadic() && i == sig.Params().Len()-1,
+		}
+		switch v.Type().Underlying().(type) {
+		case *types.Slice, *types.Map:
+			p.typedNil = p.typ + "(nil)"
+		case *types.Pointer, *types.Chan, *types.Signature:
+			p.typedNil = "(" + p.typ + ")(nil)"
+		}
+		rv = append(rv, p)
+	}
+	return rv
+}
+
+// Returns true if the supplied interface has a method with the supplied name.
+func hasMethod(iface *types.Interface, name string) bool {
+	for i := range iface.NumMethods() {
+		if iface.Method(i).Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the name of the supplied object as it is referred to from the
+// generated file.
+func (g *generator) qualifiedName(obj types.Object) string {
+	if q := g.qualifier(obj.Pkg()); q != "" {
+		return q + "." + obj.Name()
+	}
+	return obj.Name()
+}
+
+func (g *generator) typeString(t types.Type) string {
+	return types.TypeString(t, g.qualifier)
+}
+
+// Returns the name that the supplied package is referred to by from the
+// generated file, registering it as an import.
+func (g *generator) qualifier(p *types.Package) string {
+	if p == nil || (p == g.pkg && g.outPkg == g.pkg.Name()) {
+		return ""
+	}
+	g.imports[p.Path()] = p.Name()
+	return p.Name()
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// Returns the formatted source of the generated file.
+func (g *generator) file() ([]byte, error) {
+	var rv bytes.Buffer
+	fmt.Fprintf(&rv, "// Code generated by sbtestgen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", g.outPkg)
+	paths := make([]string, 0, len(g.imports))
+	for iterPath := range g.imports {
+		paths = append(paths, iterPath)
+	}
+	// Standard library imports are listed first, as goimports would.
+	slices.SortFunc(paths, func(a string, b string) int {
+		if isStd(a) != isStd(b) {
+			if isStd(a) {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+	for i, iterPath := range paths {
+		if i > 0 && isStd(iterPath) != isStd(paths[i-1]) {
+			rv.WriteString("\n")
+		}
+		if name := g.imports[iterPath]; name != filepath.Base(iterPath) {
+			fmt.Fprintf(&rv, "\t%s %q\n", name, iterPath)
+		} else {
+			fmt.Fprintf(&rv, "\t%q\n", iterPath)
+		}
+	}
+	rv.WriteString(")\n")
+	rv.Write(g.buf.Bytes())
+	return format.Source(rv.Bytes())
+}
+
+func isStd(path string) bool {
+	return !strings.Contains(strings.Split(path, "/")[0], ".")
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+)
+
+// Generates the mocks of the supplied interfaces declared in src and returns
+// the generated source, type checked together with src.
+func generate(t *testing.T, src string, names ...string) (string, error) {
+	t.Helper()
+	dir := t.TempDir()
+	sbtest.Nil(t, os.WriteFile(filepath.Join(dir, "src.go"), []byte(src), 0o644))
+	pkg, err := loadPackage(dir, "mock_test.go", "")
+	sbtest.Nil(t, err)
+	g := &generator{pkg: pkg, outPkg: pkg.Name(), imports: map[string]string{}}
+	for _, iterName := range names {
+		if err := g.genMock(iterName); err != nil {
+			return "", err
+		}
+	}
+	out, err := g.file()
+	sbtest.Nil(t, err)
+
+	fset := token.NewFileSet()
+	files := []*ast.File{}
+	for _, iterSrc := range []string{src, string(out)} {
+		file, err := parser.ParseFile(fset, "", iterSrc, 0)
+		sbtest.Nil(t, err)
+		files = append(files, file)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check(pkg.Path(), fset, files, nil)
+	sbtest.Nil(t, err)
+	return string(out), nil
+}
+
+func TestGenerateReservedNames(t *testing.T) {
+	out, err := generate(t, `package gen
+
+import "io"
+
+type Store interface {
+	Get(sbmock string, sbtest int, testing bool, io io.Reader, m, rets int) error
+	Put(int string, a0 string, _ []byte)
+}
+`, "Store")
+	sbtest.Nil(t, err)
+	sbtest.True(t, strings.Contains(
+		out, "Get(a0 string, a1 int, a2 bool, a3 io.Reader, a4 int, a5 int) error",
+	))
+	sbtest.True(t, strings.Contains(out, "Put(a0 string, a1 string, a2 []byte)"))
+}
+
+func TestGenerateMethodClashes(t *testing.T) {
+	_, err := generate(t, `package gen
+
+type Store interface {
+	Mock() int
+}
+`, "Store")
+	sbtest.True(t, err != nil && strings.Contains(err.Error(), "method named Mock"))
+
+	_, err = generate(t, `package gen
+
+type Store interface {
+	Get() int
+	OnGet()
+}
+`, "Store")
+	sbtest.True(t, err != nil && strings.Contains(err.Error(), "method named OnGet"))
+
+	_, err = generate(t, `package gen
+
+type Store interface {
+	Called() int
+	On()
+}
+`, "Store")
+	sbtest.Nil(t, err)
+}
+
+const generatedSrc = `package gen
+
+import (
+	"context"
+	"io"
+)
+
+type Store interface {
+	Get(ctx context.Context, key string) (string, error)
+	Put(string, []byte) error
+	Close()
+	Multi(m int, rest ...string) (io.Reader, bool)
+}
+
+type Other interface {
+	F(p *int, c chan<- int, fn func(int) error, mp map[string]int, xs ...int)
+}
+`
+
+const generatedTestSrc = `package gen_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+	"github.com/barbell-math/smoothbrain-test/sbmock"
+	"gen"
+)
+
+func TestGenerated(t *testing.T) {
+	m := NewMockStore(t, sbmock.Strict())
+	m.OnGet(sbmock.Any[context.Context](), "a").Return("1", nil).Once()
+	m.OnGet(sbmock.Any[context.Context](), sbmock.Any[string]()).ReturnFunc(
+		func(ctx context.Context, key string) (string, error) {
+			return key + "!", nil
+		},
+	)
+	m.OnPut("x", nil).Return(errors.New("full"))
+	m.OnMulti(1, []string{"a", "b"}).Return(nil, true)
+	m.OnClose()
+
+	var s gen.Store = m
+	v, err := s.Get(context.Background(), "a")
+	sbtest.Eq(t, "1", v)
+	sbtest.Nil(t, err)
+	v, _ = s.Get(context.Background(), "b")
+	sbtest.Eq(t, "b!", v)
+	sbtest.Eq(t, "full", s.Put("x", nil).Error())
+	r, ok := s.Multi(1, "a", "b")
+	sbtest.Nil(t, r)
+	sbtest.True(t, ok)
+	s.Close()
+
+	o := NewMockOther(t)
+	o.OnF(nil, nil, nil, nil, []int{1})
+	var other gen.Other = o
+	other.F(nil, nil, nil, nil, 1)
+	sbtest.Eq(t, 1, len(o.Calls()))
+}
+`
+
+func TestGeneratedMocksRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds and runs a separate module")
+	}
+	sbtest.SkipWithoutBinary(t, "go")
+	root, err := filepath.Abs("..")
+	sbtest.Nil(t, err)
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"go.mod": "module gen\n\ngo 1.24.1\n\n" +
+			"require github.com/barbell-math/smoothbrain-test v0.0.0\n\n" +
+			"replace github.com/barbell-math/smoothbrain-test => " + root + "\n",
+		"gen.go":      generatedSrc,
+		"gen_test.go": generatedTestSrc,
+	} {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644)
+		sbtest.Nil(t, err)
+	}
+
+	t.Chdir(dir)
+	sbtest.Nil(t, run([]string{"Store", "Other"}, "mock_gen_test.go", "gen_test"))
+	cmd := exec.Command("go", "test", "-mod=mod", ".")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("the generated mocks failed: %v\n%s", err, out)
+	}
+}
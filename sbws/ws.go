@@ -0,0 +1,239 @@
+// Helpers for testing WebSocket code: servers that record the messages they
+// receive, and assertions on the messages and close codes seen by a
+// connection.
+package sbws
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	sbtest "github.com/barbell-math/smoothbrain-test"
+	"github.com/gorilla/websocket"
+)
+
+type (
+	// A WebSocket server that is started by [StartWSServer] or
+	// [StartWSEchoServer]. The server performs the handshake for every
+	// connection and passes the resulting connection to its handler. All
+	// methods are safe for concurrent use.
+	WSServer struct {
+		// The ws:// URL of the server.
+		URL string
+
+		srv      *httptest.Server
+		mu       sync.Mutex
+		conns    []*websocket.Conn
+		messages []WSMessage
+	}
+
+	// A message that was received by a [WSServer] created with
+	// [StartWSEchoServer].
+	WSMessage struct {
+		// The type of the message, either [websocket.TextMessage] or
+		// [websocket.BinaryMessage].
+		Type int
+		Data []byte
+	}
+)
+
+// Starts a WebSocket server that calls the supplied handler with every
+// connection it accepts. The connection is closed once the handler returns.
+// The server and all of its connections are closed when the test completes.
+//
+//	srv := sbws.StartWSServer(t, func(conn *websocket.Conn) {
+//		conn.WriteMessage(websocket.TextMessage, []byte("hello"))
+//	})
+//	conn := srv.Dial(t)
+//	sbws.WSReceives(t, conn, "hello", time.Second)
+func StartWSServer(t testing.TB, handler func(conn *websocket.Conn)) *WSServer {
+	t.Helper()
+	rv := &WSServer{}
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool { return true },
+	}
+	rv.srv = httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			rv.mu.Lock()
+			rv.conns = append(rv.conns, conn)
+			rv.mu.Unlock()
+			defer conn.Close()
+			handler(conn)
+		},
+	))
+	rv.URL = "ws" + strings.TrimPrefix(rv.srv.URL, "http")
+	t.Cleanup(func() {
+		rv.mu.Lock()
+		for _, iterConn := range rv.conns {
+			iterConn.Close()
+		}
+		rv.mu.Unlock()
+		rv.srv.Close()
+	})
+	return rv
+}
+
+// Starts a WebSocket server that records every message it receives and writes
+// it back to the client unchanged. The recorded messages are available from
+// [WSServer.Messages]. Refer to [StartWSServer] for the lifetime of the
+// server.
+func StartWSEchoServer(t testing.TB) *WSServer {
+	t.Helper()
+	var rv *WSServer
+	rv = StartWSServer(t, func(conn *websocket.Conn) {
+		for {
+			typ, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			rv.mu.Lock()
+			rv.messages = append(rv.messages, WSMessage{Type: typ, Data: data})
+			rv.mu.Unlock()
+			if err := conn.WriteMessage(typ, data); err != nil {
+				return
+			}
+		}
+	})
+	return rv
+}
+
+// Opens a client connection to the server. The test fails if the handshake
+// does not succeed. The connection is closed when the test completes.
+func (s *WSServer) Dial(t testing.TB) *websocket.Conn {
+	t.Helper()
+	conn, resp, err := websocket.DefaultDialer.Dial(s.URL, nil)
+	if resp != nil && resp.Body != nil {
+		resp.Body.Close()
+	}
+	if err != nil {
+		f, line := sbtest.CallerLoc()
+		sbtest.FormatError(
+			t, nil, err,
+			fmt.Sprintf("The WebSocket handshake failed | URL: %s", s.URL),
+			f, line,
+		)
+		return nil
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// Returns a copy of all messages that have been received by an echo server so
+// far, in the order they were received.
+func (s *WSServer) Messages() []WSMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]WSMessage{}, s.messages...)
+}
+
+// Tests that the next message read from the supplied connection within the
+// supplied timeout has the expected data. Both text and binary messages are
+// compared against the expected data. Once a read has timed out the
+// connection cannot be read from again, so a failure caused by a timeout will
+// also fail any later reads.
+func WSReceives(
+	t testing.TB,
+	conn *websocket.Conn,
+	expected string,
+	timeout time.Duration,
+) {
+	t.Helper()
+	f, line := sbtest.CallerLoc()
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	defer conn.SetReadDeadline(time.Time{})
+	typ, data, err := conn.ReadMessage()
+	if err != nil {
+		sbtest.FormatError(
+			t, expected, err,
+			fmt.Sprintf(
+				"A message was not received within the timeout | Timeout: %s",
+				timeout,
+			),
+			f, line,
+		)
+		return
+	}
+	if string(data) != expected {
+		sbtest.FormatError(
+			t, expected, string(data),
+			fmt.Sprintf(
+				"The received message did not have the expected data | Type: %s",
+				fmtMessageType(typ),
+			),
+			f, line,
+		)
+	}
+}
+
+// Tests that the supplied connection is closed by the peer with the expected
+// close code within the supplied timeout. Any messages received before the
+// close frame are discarded and listed on failure.
+func WSClosedWithCode(
+	t testing.TB,
+	conn *websocket.Conn,
+	code int,
+	timeout time.Duration,
+) {
+	t.Helper()
+	f, line := sbtest.CallerLoc()
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	defer conn.SetReadDeadline(time.Time{})
+	var discarded strings.Builder
+	for {
+		typ, data, err := conn.ReadMessage()
+		if err == nil {
+			fmt.Fprintf(&discarded, "\n  %s: %q", fmtMessageType(typ), data)
+			continue
+		}
+		var closeErr *websocket.CloseError
+		if !errors.As(err, &closeErr) {
+			sbtest.FormatError(
+				t, code, err,
+				fmt.Sprintf(
+					"The connection was not closed within the timeout | Timeout: %s\nDiscarded Messages:%s",
+					timeout, fmtDiscarded(&discarded),
+				),
+				f, line,
+			)
+			return
+		}
+		if closeErr.Code != code {
+			sbtest.FormatError(
+				t, code, closeErr.Code,
+				fmt.Sprintf(
+					"The connection was closed with the wrong code | Reason: %s\nDiscarded Messages:%s",
+					closeErr.Text, fmtDiscarded(&discarded),
+				),
+				f, line,
+			)
+		}
+		return
+	}
+}
+
+func fmtMessageType(typ int) string {
+	switch typ {
+	case websocket.TextMessage:
+		return "text"
+	case websocket.BinaryMessage:
+		return "binary"
+	default:
+		return fmt.Sprintf("unknown(%d)", typ)
+	}
+}
+
+func fmtDiscarded(sb *strings.Builder) string {
+	if sb.Len() == 0 {
+		return " <no messages>"
+	}
+	return sb.String()
+}
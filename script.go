@@ -0,0 +1,370 @@
+package sbtest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type (
+	// The state of a single script that is being run by [RunScripts].
+	scriptState struct {
+		t       testing.TB
+		path    string
+		dir     string
+		env     []string
+		timeout time.Duration
+		stdin   string
+		stdout  string
+		stderr  string
+	}
+)
+
+// Runs every script that matches the supplied glob pattern as a subtest named
+// after the script file. This turns end to end tests of command line tools
+// into data rather than Go code. A relative pattern is resolved relative to the
+// directory of the file that calls RunScripts, in the same way as [LoadBytes].
+//
+//	func TestCLI(t *testing.T) {
+//		sbtest.RunScripts(t, "testdata/scripts/*.txtar", 10*time.Second)
+//	}
+//
+// Each script is a txtar archive, refer to [LoadTxtar] for the format. The
+// files of the archive are expanded into a new temporary work directory and
+// the comment of the archive is the script. Each line of the script is a
+// command, blank lines and lines starting with '#' are ignored. Arguments are
+// separated by spaces, text in single quotes is kept together as one argument,
+// and $VAR and ${VAR} are expanded from the script environment. $WORK is the
+// work directory. A command prefixed with '!' is expected to fail. The
+// supported commands are:
+//   - exec prog args...: Runs the program in the current directory. It must
+//     succeed, or fail with '!', and finish within the timeout.
+//   - stdin file: Uses the contents of the file as the stdin of the next exec.
+//   - stdout regex: The stdout of the last exec must match the regex, or must
+//     not match with '!'. The regex is in multi-line mode, so ^ and $ match
+//     at the start and end of every line.
+//   - stderr regex: The same as stdout but for stderr.
+//   - cmp a b: The files must have equal contents. The names stdout and
+//     stderr refer to the output of the last exec.
+//   - exists path...: The paths must exist, or must not exist with '!'.
+//   - cd dir: Changes the current directory of the script.
+//   - env KEY=VALUE...: Sets variables in the script environment.
+//
+// Failures are reported at the line of the script that failed and stop the
+// script.
+func RunScripts(t *testing.T, pattern string, timeout time.Duration) {
+	t.Helper()
+	f, line := callerLoc()
+	pattern = fixturePath(pattern, f)
+	paths, err := filepath.Glob(pattern)
+	if err == nil && len(paths) == 0 {
+		err = errors.New("no files matched the pattern")
+	}
+	if err != nil {
+		FormatError(
+			t, nil, errChain(err),
+			fmt.Sprintf("The scripts could not be found | Pattern: %s", pattern),
+			f, line,
+		)
+		return
+	}
+	for _, iterPath := range paths {
+		name := strings.TrimSuffix(filepath.Base(iterPath), filepath.Ext(iterPath))
+		t.Run(name, func(st *testing.T) {
+			st.Helper()
+			runScript(st, iterPath, timeout, f, line)
+		})
+	}
+}
+
+func runScript(
+	t testing.TB,
+	path string,
+	timeout time.Duration,
+	f string,
+	line int,
+) {
+	t.Helper()
+	data, ok := readFile(t, path, f, line)
+	if !ok {
+		return
+	}
+	work := LoadTxtar(t, path)
+	s := &scriptState{
+		t:       t,
+		path:    path,
+		dir:     work,
+		env:     []string{"WORK=" + work},
+		timeout: timeout,
+	}
+	for i, iterLine := range strings.Split(txtarComment(data), "\n") {
+		iterLine = strings.TrimSpace(iterLine)
+		if iterLine == "" || strings.HasPrefix(iterLine, "#") {
+			continue
+		}
+		if !s.run(iterLine, i+1) {
+			return
+		}
+	}
+}
+
+// Runs a single line of the script, returning false if it failed.
+func (s *scriptState) run(line string, lineNum int) bool {
+	s.t.Helper()
+	neg := false
+	if rest, ok := strings.CutPrefix(line, "!"); ok {
+		neg, line = true, strings.TrimSpace(rest)
+	}
+	args := s.splitArgs(line)
+	if len(args) == 0 {
+		return s.fail(nil, line, "The command was empty.", lineNum)
+	}
+	cmd, args := args[0], args[1:]
+	if neg && !slices.Contains([]string{"exec", "stdout", "stderr", "exists"}, cmd) {
+		return s.fail(nil, cmd, "The command cannot be negated.", lineNum)
+	}
+
+	switch cmd {
+	case "exec":
+		return s.exec(line, args, neg, lineNum)
+	case "stdin":
+		if len(args) != 1 {
+			return s.fail(
+				"stdin file", line,
+				"The command had the wrong number of arguments.",
+				lineNum,
+			)
+		}
+		data, err := os.ReadFile(s.abs(args[0]))
+		if err != nil {
+			return s.fail(nil, errChain(err), "The stdin file could not be read.", lineNum)
+		}
+		s.stdin = string(data)
+	case "stdout", "stderr":
+		if len(args) != 1 {
+			return s.fail(
+				cmd+" regex", line,
+				"The command had the wrong number of arguments.",
+				lineNum,
+			)
+		}
+		re, err := regexp.Compile("(?m)" + args[0])
+		if err != nil {
+			return s.fail(nil, errChain(err), "The regex could not be compiled.", lineNum)
+		}
+		out := s.stdout
+		if cmd == "stderr" {
+			out = s.stderr
+		}
+		if re.MatchString(out) == neg {
+			return s.fail(
+				args[0], out,
+				fmt.Sprintf(
+					"The %s of the last exec did not match as expected | Negated: %v",
+					cmd, neg,
+				),
+				lineNum,
+			)
+		}
+	case "cmp":
+		if len(args) != 2 {
+			return s.fail(
+				"cmp a b", line,
+				"The command had the wrong number of arguments.",
+				lineNum,
+			)
+		}
+		a, ok := s.readCmpArg(args[0], lineNum)
+		if !ok {
+			return false
+		}
+		b, ok := s.readCmpArg(args[1], lineNum)
+		if !ok {
+			return false
+		}
+		if a != b {
+			return s.fail(
+				a, b,
+				fmt.Sprintf(
+					"The files were not equal | Files: %s %s\nDiff:%s",
+					args[0], args[1], lineDiff(a, b),
+				),
+				lineNum,
+			)
+		}
+	case "exists":
+		for _, iterPath := range args {
+			_, err := os.Stat(s.abs(iterPath))
+			if (err == nil) == neg {
+				return s.fail(
+					!neg, neg,
+					fmt.Sprintf(
+						"The path did not exist as expected | Path: %s | Negated: %v",
+						iterPath, neg,
+					),
+					lineNum,
+				)
+			}
+		}
+	case "cd":
+		if len(args) != 1 {
+			return s.fail(
+				"cd dir", line,
+				"The command had the wrong number of arguments.",
+				lineNum,
+			)
+		}
+		s.dir = s.abs(args[0])
+	case "env":
+		for _, iterArg := range args {
+			if !strings.Contains(iterArg, "=") {
+				return s.fail("KEY=VALUE", iterArg, "The env argument was malformed.", lineNum)
+			}
+			s.env = append(s.env, iterArg)
+		}
+	default:
+		return s.fail(nil, cmd, "The script contained an unknown command.", lineNum)
+	}
+	return true
+}
+
+func (s *scriptState) exec(line string, args []string, neg bool, lineNum int) bool {
+	s.t.Helper()
+	if len(args) == 0 {
+		return s.fail(
+			"exec prog args...", line,
+			"The command had the wrong number of arguments.",
+			lineNum,
+		)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = s.dir
+	cmd.Env = append(os.Environ(), s.env...)
+	cmd.Stdin = strings.NewReader(s.stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	s.stdin, s.stdout, s.stderr = "", stdout.String(), stderr.String()
+
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() != nil:
+		return s.fail(
+			fmt.Sprintf("finished within %s", s.timeout), "killed",
+			fmt.Sprintf("The command did not finish before the timeout expired.%s", s.output()),
+			lineNum,
+		)
+	case err != nil && !errors.As(err, &exitErr):
+		return s.fail(nil, errChain(err), "The command could not be run.", lineNum)
+	case (err != nil) != neg:
+		return s.fail(
+			neg, err != nil,
+			fmt.Sprintf(
+				"The command did not fail as expected | Exit Code: %d%s",
+				cmd.ProcessState.ExitCode(), s.output(),
+			),
+			lineNum,
+		)
+	}
+	return true
+}
+
+func (s *scriptState) readCmpArg(name string, lineNum int) (string, bool) {
+	s.t.Helper()
+	switch name {
+	case "stdout":
+		return s.stdout, true
+	case "stderr":
+		return s.stderr, true
+	}
+	data, err := os.ReadFile(s.abs(name))
+	if err != nil {
+		return "", s.fail(nil, errChain(err), "The file could not be read.", lineNum)
+	}
+	return string(data), true
+}
+
+// Splits the supplied line into arguments, keeping single quoted text together
+// and expanding variables outside of single quotes.
+func (s *scriptState) splitArgs(line string) []string {
+	rv := []string{}
+	var sb strings.Builder
+	inArg, inQuote := false, false
+	for _, iterPart := range strings.SplitAfter(line, "'") {
+		text, quote := strings.CutSuffix(iterPart, "'")
+		if inQuote {
+			sb.WriteString(text)
+			inArg = true
+		} else {
+			for i, iterField := range strings.Split(os.Expand(text, s.lookupEnv), " ") {
+				if i > 0 && inArg {
+					rv = append(rv, sb.String())
+					sb.Reset()
+					inArg = false
+				}
+				if iterField != "" {
+					sb.WriteString(iterField)
+					inArg = true
+				}
+			}
+		}
+		if quote {
+			inQuote = !inQuote
+		}
+	}
+	if inArg {
+		rv = append(rv, sb.String())
+	}
+	return rv
+}
+
+func (s *scriptState) lookupEnv(key string) string {
+	for i := len(s.env) - 1; i >= 0; i-- {
+		if v, ok := strings.CutPrefix(s.env[i], key+"="); ok {
+			return v
+		}
+	}
+	return os.Getenv(key)
+}
+
+func (s *scriptState) abs(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(s.dir, path)
+}
+
+func (s *scriptState) output() string {
+	return fmt.Sprintf("\nStdout: %s\nStderr: %s", s.stdout, s.stderr)
+}
+
+// Reports a failure at the supplied line of the script and returns false.
+func (s *scriptState) fail(expected any, got any, msg string, lineNum int) bool {
+	s.t.Helper()
+	FormatError(s.t, expected, got, msg, s.path, lineNum)
+	return false
+}
+
+// Returns the comment of the supplied txtar archive, which is everything
+// before the first file marker.
+func txtarComment(data []byte) string {
+	var sb strings.Builder
+	for _, iterLine := range strings.SplitAfter(string(data), "\n") {
+		if _, ok := txtarMarker(iterLine); ok {
+			break
+		}
+		sb.WriteString(iterLine)
+	}
+	return sb.String()
+}
@@ -0,0 +1,238 @@
+package sbtest
+
+import (
+	"iter"
+	"math"
+	"reflect"
+)
+
+// The maximum number of candidate values that [Shrink] will try.
+const maxShrinkAttempts = 1000
+
+// Shrinks a value that makes a test fail toward a minimal value that still
+// fails, so the cause of a failure found with random data is easier to see.
+// The supplied fails function must return true if the value it is given still
+// fails. Numbers are shrunk toward zero, strings, slices, and maps by removing
+// elements, pointers toward nil, and the elements and exported fields of
+// compound values are shrunk recursively. Shrinking is greedy, the first
+// smaller candidate that still fails is kept and shrinking restarts from it,
+// until no candidate fails or a bounded number of candidates have been tried.
+// The shrunk value and the number of times a smaller failing value was found
+// are returned.
+//
+//	min, _ := sbtest.Shrink([]int{10, 3, 7, 42}, func(v []int) bool {
+//		return slices.Contains(v, 42)
+//	})
+//	// min == []int{42}
+func Shrink[T any](v T, fails func(v T) bool) (T, int) {
+	cur := reflect.ValueOf(&v).Elem()
+	steps := 0
+	for attempts, improved := 0, true; improved && attempts < maxShrinkAttempts; {
+		improved = false
+		for iterCand := range shrinkCandidates(cur) {
+			attempts++
+			if fails(shrinkValueAs[T](iterCand)) {
+				cur, improved = iterCand, true
+				steps++
+				break
+			}
+			if attempts >= maxShrinkAttempts {
+				break
+			}
+		}
+	}
+	return shrinkValueAs[T](cur), steps
+}
+
+func shrinkValueAs[T any](v reflect.Value) T {
+	var rv T
+	reflect.ValueOf(&rv).Elem().Set(v)
+	return rv
+}
+
+// Returns new values of the same type as v that are smaller than v, roughly in
+// order from the most to the least aggressive shrink. The supplied value is
+// never modified.
+func shrinkCandidates(v reflect.Value) iter.Seq[reflect.Value] {
+	return func(yield func(reflect.Value) bool) {
+		typ := v.Type()
+		switch v.Kind() {
+		case reflect.Bool:
+			if v.Bool() {
+				yield(reflect.Zero(typ))
+			}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			for d := v.Int(); d != 0; d /= 2 {
+				rv := reflect.New(typ).Elem()
+				rv.SetInt(v.Int() - d)
+				if !yield(rv) {
+					return
+				}
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+			reflect.Uint64, reflect.Uintptr:
+			for d := v.Uint(); d != 0; d /= 2 {
+				rv := reflect.New(typ).Elem()
+				rv.SetUint(v.Uint() - d)
+				if !yield(rv) {
+					return
+				}
+			}
+		case reflect.Float32, reflect.Float64:
+			f := v.Float()
+			for _, iterCand := range []float64{0, math.Trunc(f), f / 2} {
+				if iterCand == f || math.IsNaN(f) {
+					continue
+				}
+				rv := reflect.New(typ).Elem()
+				rv.SetFloat(iterCand)
+				if !yield(rv) {
+					return
+				}
+			}
+		case reflect.String:
+			runes := []rune(v.String())
+			build := func(keep func(i int) bool) []rune {
+				rv := []rune{}
+				for i, iterRune := range runes {
+					if keep(i) {
+						rv = append(rv, iterRune)
+					}
+				}
+				return rv
+			}
+			for iterRunes := range shrinkRemovals(len(runes), build) {
+				rv := reflect.New(typ).Elem()
+				rv.SetString(string(iterRunes))
+				if !yield(rv) {
+					return
+				}
+			}
+		case reflect.Slice:
+			if v.IsNil() {
+				return
+			}
+			build := func(keep func(i int) bool) reflect.Value {
+				rv := reflect.MakeSlice(typ, 0, v.Len())
+				for i := range v.Len() {
+					if keep(i) {
+						rv = reflect.Append(rv, v.Index(i))
+					}
+				}
+				return rv
+			}
+			for iterCand := range shrinkRemovals(v.Len(), build) {
+				if !yield(iterCand) {
+					return
+				}
+			}
+			shrinkElems(v, yield, func() reflect.Value {
+				rv := reflect.MakeSlice(typ, v.Len(), v.Len())
+				reflect.Copy(rv, v)
+				return rv
+			})
+		case reflect.Array:
+			shrinkElems(v, yield, func() reflect.Value {
+				rv := reflect.New(typ).Elem()
+				rv.Set(v)
+				return rv
+			})
+		case reflect.Map:
+			if v.IsNil() {
+				return
+			}
+			keys := v.MapKeys()
+			copyMap := func(skip int) reflect.Value {
+				rv := reflect.MakeMapWithSize(typ, len(keys))
+				for i, iterKey := range keys {
+					if i != skip {
+						rv.SetMapIndex(iterKey, v.MapIndex(iterKey))
+					}
+				}
+				return rv
+			}
+			if len(keys) > 0 && !yield(reflect.MakeMap(typ)) {
+				return
+			}
+			for i := range keys {
+				if len(keys) > 1 && !yield(copyMap(i)) {
+					return
+				}
+			}
+			for _, iterKey := range keys {
+				for iterCand := range shrinkCandidates(v.MapIndex(iterKey)) {
+					rv := copyMap(-1)
+					rv.SetMapIndex(iterKey, iterCand)
+					if !yield(rv) {
+						return
+					}
+				}
+			}
+		case reflect.Pointer:
+			if v.IsNil() {
+				return
+			}
+			if !yield(reflect.Zero(typ)) {
+				return
+			}
+			for iterCand := range shrinkCandidates(v.Elem()) {
+				rv := reflect.New(typ.Elem())
+				rv.Elem().Set(iterCand)
+				if !yield(rv) {
+					return
+				}
+			}
+		case reflect.Struct:
+			for i := range v.NumField() {
+				if !typ.Field(i).IsExported() {
+					continue
+				}
+				for iterCand := range shrinkCandidates(v.Field(i)) {
+					rv := reflect.New(typ).Elem()
+					rv.Set(v)
+					rv.Field(i).Set(iterCand)
+					if !yield(rv) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+// Yields the result of build for every way of removing a contiguous chunk of
+// elements from a sequence of length n, starting with removing everything and
+// halving the chunk size each round.
+func shrinkRemovals[T any](
+	n int,
+	build func(keep func(i int) bool) T,
+) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for size := n; size > 0; size /= 2 {
+			for start := 0; start+size <= n; start += size {
+				keep := func(i int) bool { return i < start || i >= start+size }
+				if !yield(build(keep)) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Yields a copy of the slice or array v for every candidate of each of its
+// elements, with only that element replaced.
+func shrinkElems(
+	v reflect.Value,
+	yield func(reflect.Value) bool,
+	copyVal func() reflect.Value,
+) {
+	for i := range v.Len() {
+		for iterCand := range shrinkCandidates(v.Index(i)) {
+			rv := copyVal()
+			rv.Index(i).Set(iterCand)
+			if !yield(rv) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package sbtest
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+type shrinkArgs struct {
+	P      *int
+	M      map[string]int
+	S      string
+	hidden int
+}
+
+func TestShrinkSlice(t *testing.T) {
+	v := []int{10, 3, 7, 42}
+	got, steps := Shrink(v, func(v []int) bool { return slices.Contains(v, 42) })
+	SlicesMatch(t, []int{42}, got)
+	True(t, steps > 0)
+	SlicesMatch(t, []int{10, 3, 7, 42}, v)
+}
+
+func TestShrinkNumbers(t *testing.T) {
+	got, _ := Shrink(1000, func(v int) bool { return v >= 17 })
+	Eq(t, 17, got)
+	got, _ = Shrink(-100, func(v int) bool { return v <= -5 })
+	Eq(t, -5, got)
+	gotU, _ := Shrink(uint8(255), func(v uint8) bool { return v >= 9 })
+	Eq(t, uint8(9), gotU)
+	gotF, _ := Shrink(100.0, func(v float64) bool { return v >= 1.5 })
+	True(t, gotF >= 1.5 && gotF < 2)
+}
+
+func TestShrinkString(t *testing.T) {
+	got, _ := Shrink("aaabaaa", func(v string) bool {
+		return strings.Contains(v, "b")
+	})
+	Eq(t, "b", got)
+}
+
+func TestShrinkStruct(t *testing.T) {
+	p := 50
+	v := shrinkArgs{P: &p, M: map[string]int{"a": 1, "b": 2}, S: "xyz", hidden: 4}
+	got, _ := Shrink(v, func(v shrinkArgs) bool { return v.P != nil && *v.P >= 3 })
+	True(t, got.P != nil && *got.P == 3)
+	Eq(t, 0, len(got.M))
+	Eq(t, "", got.S)
+	Eq(t, 4, got.hidden)
+	Eq(t, 50, p)
+}
+
+func TestShrinkNoFailingCandidate(t *testing.T) {
+	v := []int{1, 2, 3}
+	got, steps := Shrink(v, func(v []int) bool { return slices.Equal(v, []int{1, 2, 3}) })
+	Eq(t, 0, steps)
+	SlicesMatch(t, v, got)
+}
+
+func TestShrinkBoundedAttempts(t *testing.T) {
+	calls := 0
+	Shrink(make([]int, 5000), func(v []int) bool {
+		calls++
+		return false
+	})
+	Eq(t, maxShrinkAttempts, calls)
+}
@@ -0,0 +1,91 @@
+package sbtest
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"testing"
+	"time"
+)
+
+const (
+	// The interval at which the condition supplied to [SendSignalAndAssert] is
+	// checked.
+	signalPollInterval = 5 * time.Millisecond
+)
+
+// Tests that the current process reacts to the supplied signal. trigger is
+// called first and is where the code under test should install its signal
+// handler, for example by starting a server that shuts down gracefully on
+// SIGTERM. trigger must not return until the handler is installed, otherwise
+// the signal may arrive before the code under test is listening for it. The
+// signal is then sent to the current process and cond is polled until it
+// returns true or the timeout expires. A nil trigger is allowed when the
+// handler is already installed.
+//
+//	sbtest.SendSignalAndAssert(
+//		t, syscall.SIGTERM,
+//		func() {
+//			go srv.Run()
+//			<-srv.Ready()
+//		},
+//		func() bool { return srv.Stopped() },
+//		time.Second,
+//	)
+//
+// A listener for the signal is registered for the duration of the test so
+// that the signal never triggers its default action, which for most signals
+// would kill the test binary. Only that listener is removed when the test
+// completes, handlers that were installed before the test or by the code
+// under test are left in place. Because signals are process wide state, tests
+// that call this must not run in parallel. Sending signals other than
+// [os.Kill] is not supported on Windows.
+func SendSignalAndAssert(
+	t testing.TB,
+	sig os.Signal,
+	trigger func(),
+	cond func() bool,
+	timeout time.Duration,
+) {
+	t.Helper()
+	f, line := callerLoc()
+	guard := make(chan os.Signal, 1)
+	signal.Notify(guard, sig)
+	t.Cleanup(func() { signal.Stop(guard) })
+
+	if trigger != nil {
+		trigger()
+	}
+	proc, err := os.FindProcess(os.Getpid())
+	if err == nil {
+		err = proc.Signal(sig)
+	}
+	if err != nil {
+		FormatError(
+			t, nil, errChain(err),
+			fmt.Sprintf("The signal could not be sent | Signal: %s", sig),
+			f, line,
+		)
+		return
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	ticker := time.NewTicker(signalPollInterval)
+	defer ticker.Stop()
+	for !cond() {
+		select {
+		case <-ticker.C:
+		case <-timer.C:
+			FormatError(
+				t, true, false,
+				fmt.Sprintf(
+					"The condition was not met after the signal was sent | Signal: %s | Timeout: %s",
+					sig, timeout,
+				),
+				f, line,
+			)
+			return
+		}
+	}
+}
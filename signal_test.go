@@ -0,0 +1,63 @@
+package sbtest
+
+import (
+	"os"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSendSignalAndAssert(t *testing.T) {
+	SkipOnOS(t, "windows")
+	// A handler installed before the test must still be installed after it.
+	before := make(chan os.Signal, 1)
+	signal.Notify(before, os.Interrupt)
+	defer signal.Stop(before)
+
+	t.Run("handled", func(t *testing.T) {
+		var handled atomic.Bool
+		var ch chan os.Signal
+		SendSignalAndAssert(
+			t, os.Interrupt,
+			func() {
+				ch = make(chan os.Signal, 1)
+				signal.Notify(ch, os.Interrupt)
+				go func() {
+					<-ch
+					handled.Store(true)
+				}()
+			},
+			handled.Load,
+			time.Second,
+		)
+		signal.Stop(ch)
+	})
+	<-before
+
+	proc, err := os.FindProcess(os.Getpid())
+	Nil(t, err)
+	Nil(t, proc.Signal(os.Interrupt))
+	select {
+	case <-before:
+	case <-time.After(time.Second):
+		t.Fatal("the handler installed before the test was removed")
+	}
+}
+
+func TestSendSignalAndAssertConditionNotMet(t *testing.T) {
+	SkipOnOS(t, "windows")
+	msgs := recordFailures(t, func(t testing.TB) {
+		SendSignalAndAssert(
+			t, os.Interrupt, nil, func() bool { return false },
+			20*time.Millisecond,
+		)
+	})
+	Eq(t, 1, len(msgs))
+	True(t, strings.Contains(
+		msgs[0],
+		"The condition was not met after the signal was sent | "+
+			"Signal: interrupt | Timeout: 20ms",
+	))
+}
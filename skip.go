@@ -0,0 +1,69 @@
+package sbtest
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+// The prefix of the message that every skip helper in this package skips the
+// test with. The full message has the form
+//
+//	sbtest: skipped | Condition: <condition> | Reason: <reason>
+//
+// where the condition is one of short, env, os, or binary, so skipped tests
+// can be found and grouped by searching the verbose output of go test.
+const SkipPrefix = "sbtest: skipped"
+
+// Skips the test if the -short flag was set, refer to [testing.Short].
+func SkipIfShort(t testing.TB) {
+	t.Helper()
+	if testing.Short() {
+		skip(t, "short", "the -short flag was set")
+	}
+}
+
+// Skips the test unless the environment variable with the supplied key is set
+// to a non-empty value. This is intended for tests that need external
+// resources and are only run when opted into.
+//
+//	sbtest.SkipUnlessEnv(t, "INTEGRATION")
+func SkipUnlessEnv(t testing.TB, key string) {
+	t.Helper()
+	if os.Getenv(key) == "" {
+		skip(
+			t, "env",
+			fmt.Sprintf("the %s environment variable was not set", key),
+		)
+	}
+}
+
+// Skips the test if it is running on any of the supplied operating systems,
+// which are compared against [runtime.GOOS].
+//
+//	sbtest.SkipOnOS(t, "windows", "plan9")
+func SkipOnOS(t testing.TB, goos ...string) {
+	t.Helper()
+	if slices.Contains(goos, runtime.GOOS) {
+		skip(t, "os", fmt.Sprintf("the test does not run on %s", runtime.GOOS))
+	}
+}
+
+// Skips the test if the named binary cannot be found in the directories named
+// by the PATH environment variable, refer to [exec.LookPath].
+//
+//	sbtest.SkipWithoutBinary(t, "docker")
+func SkipWithoutBinary(t testing.TB, name string) {
+	t.Helper()
+	if _, err := exec.LookPath(name); err != nil {
+		skip(t, "binary", fmt.Sprintf("%s was not found in PATH", name))
+	}
+}
+
+func skip(t testing.TB, condition string, reason string) {
+	t.Helper()
+	t.Skipf("%s | Condition: %s | Reason: %s", SkipPrefix, condition, reason)
+}
@@ -0,0 +1,172 @@
+package sbtest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type (
+	// An [io.Writer] that records every call to Write so that code producing
+	// a stream of output can be verified precisely. Errors can be injected on
+	// specific calls to test how the code handles failing writers. All methods
+	// are safe for concurrent use.
+	SpyWriter struct {
+		mu      sync.Mutex
+		calls   []WriteCall
+		written bytes.Buffer
+		faults  map[int]writeFault
+	}
+
+	// A single call to [SpyWriter.Write].
+	WriteCall struct {
+		// The data that was passed to Write.
+		Data []byte
+		// The number of bytes Write reported as written.
+		N int
+		// The error that Write returned.
+		Err error
+	}
+
+	writeFault struct {
+		n   int
+		err error
+	}
+)
+
+// Creates a new spy writer that accepts all writes.
+func NewSpyWriter() *SpyWriter {
+	return &SpyWriter{faults: map[int]writeFault{}}
+}
+
+// Makes the call to Write with the supplied zero based index fail with the
+// supplied error without writing anything.
+func (s *SpyWriter) FailOnCall(call int, err error) {
+	s.ShortWriteOnCall(call, 0, err)
+}
+
+// Makes the call to Write with the supplied zero based index only write the
+// first n bytes of its data and return the supplied error. If n is larger than
+// the data then all of the data is written. If fewer bytes than the data are
+// written and the error is nil then [io.ErrShortWrite] is returned, as
+// required by [io.Writer]. Panics if n is negative.
+func (s *SpyWriter) ShortWriteOnCall(call int, n int, err error) {
+	if n < 0 {
+		panic(fmt.Sprintf("sbtest: the short write length %d is negative", n))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[call] = writeFault{n: n, err: err}
+}
+
+// Implements the [io.Writer] interface.
+func (s *SpyWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	call := WriteCall{Data: slices.Clone(p), N: len(p)}
+	if fault, ok := s.faults[len(s.calls)]; ok {
+		call.N = min(fault.n, len(p))
+		call.Err = fault.err
+		if call.N < len(p) && call.Err == nil {
+			call.Err = io.ErrShortWrite
+		}
+	}
+	s.written.Write(p[:call.N])
+	s.calls = append(s.calls, call)
+	return call.N, call.Err
+}
+
+// Returns a copy of all calls that have been made to Write so far, in the
+// order they were made.
+func (s *SpyWriter) Calls() []WriteCall {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.calls)
+}
+
+// Returns all of the data that was successfully written so far.
+func (s *SpyWriter) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.written.String()
+}
+
+// Tests that Write was called exactly the expected number of times. The size
+// of each call is listed on failure.
+func (s *SpyWriter) WriteCountEq(t testing.TB, expected int) {
+	t.Helper()
+	calls := s.Calls()
+	if len(calls) != expected {
+		f, line := callerLoc()
+		FormatError(
+			t, expected, len(calls),
+			fmt.Sprintf(
+				"The writer was not called the expected number of times.\nCalls:%s",
+				fmtWriteCalls(calls),
+			),
+			f, line,
+		)
+	}
+}
+
+// Tests that the total number of bytes successfully written is equal to the
+// expected value.
+func (s *SpyWriter) BytesWrittenEq(t testing.TB, expected int) {
+	t.Helper()
+	if got := len(s.String()); got != expected {
+		f, line := callerLoc()
+		FormatError(
+			t, expected, got,
+			"The writer did not have the expected number of bytes written to it.",
+			f, line,
+		)
+	}
+}
+
+// Tests that all of the data successfully written is equal to the expected
+// value. A line based diff is shown on failure.
+func (s *SpyWriter) WrittenEq(t testing.TB, expected string) {
+	t.Helper()
+	if got := s.String(); got != expected {
+		f, line := callerLoc()
+		FormatError(
+			t, expected, got,
+			fmt.Sprintf(
+				"The data written to the writer did not match.\nDiff:%s",
+				lineDiff(expected, got),
+			),
+			f, line,
+		)
+	}
+}
+
+// Tests that the data successfully written contains the supplied substring.
+func (s *SpyWriter) WrittenContains(t testing.TB, substr string) {
+	t.Helper()
+	if got := s.String(); !strings.Contains(got, substr) {
+		f, line := callerLoc()
+		FormatError(
+			t, substr, got,
+			"The data written to the writer did not contain the expected substring.",
+			f, line,
+		)
+	}
+}
+
+func fmtWriteCalls(calls []WriteCall) string {
+	if len(calls) == 0 {
+		return " <no calls>"
+	}
+	var sb strings.Builder
+	for i, iterCall := range calls {
+		fmt.Fprintf(&sb, "\n  %d: %d bytes %q", i, len(iterCall.Data), iterCall.Data)
+		if iterCall.Err != nil {
+			fmt.Fprintf(&sb, " | Wrote: %d | Error: %v", iterCall.N, iterCall.Err)
+		}
+	}
+	return sb.String()
+}
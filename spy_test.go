@@ -0,0 +1,44 @@
+package sbtest
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSpyWriterShortWrite(t *testing.T) {
+	s := NewSpyWriter()
+	errFull := errors.New("full")
+	s.ShortWriteOnCall(0, 2, nil)
+	s.ShortWriteOnCall(1, 1, errFull)
+	s.ShortWriteOnCall(2, 10, nil)
+
+	n, err := s.Write([]byte("abcd"))
+	Eq(t, 2, n)
+	ContainsError(t, io.ErrShortWrite, err)
+	n, err = s.Write([]byte("ef"))
+	Eq(t, 1, n)
+	ContainsError(t, errFull, err)
+	n, err = s.Write([]byte("gh"))
+	Eq(t, 2, n)
+	Nil(t, err)
+	s.WrittenEq(t, "abegh")
+
+	Panics(t, func() { s.ShortWriteOnCall(3, -1, nil) })
+}
+
+func TestSpyWriterFailures(t *testing.T) {
+	s := NewSpyWriter()
+	s.FailOnCall(1, errors.New("closed"))
+	s.Write([]byte("a"))
+	s.Write([]byte("b"))
+	msgs := recordFailures(t, func(t testing.TB) { s.WriteCountEq(t, 1) })
+	Eq(t, 1, len(msgs))
+	True(t, strings.Contains(
+		msgs[0], "\n  1: 1 bytes \"b\" | Wrote: 0 | Error: closed",
+	))
+	msgs = recordFailures(t, func(t testing.TB) { s.WrittenEq(t, "ab") })
+	Eq(t, 1, len(msgs))
+	True(t, strings.Contains(msgs[0], "The data written to the writer did not match."))
+}
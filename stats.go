@@ -0,0 +1,106 @@
+package sbtest
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+type (
+	// The set of types that the statistical assertions can be used with.
+	number interface {
+		~int | ~int8 | ~int16 | ~int32 | ~int64 |
+			~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+			~float32 | ~float64
+	}
+)
+
+// Tests that the sum of the supplied data is within +/- eps distance of the
+// expected sum. The sum is computed using float64 values.
+func SumWithin[T number](t testing.TB, data []T, expectedSum float64, eps float64) {
+	t.Helper()
+	f, line := callerLoc()
+	aggregateWithin(t, "sum", sum(data), expectedSum, eps, len(data), f, line)
+}
+
+// Tests that the arithmetic mean of the supplied data is within +/- eps
+// distance of the expected mean. The test fails if data is empty.
+func MeanWithin[T number](t testing.TB, data []T, expectedMean float64, eps float64) {
+	t.Helper()
+	f, line := callerLoc()
+	if !notEmpty(t, len(data), "mean", f, line) {
+		return
+	}
+	mean := sum(data) / float64(len(data))
+	aggregateWithin(t, "mean", mean, expectedMean, eps, len(data), f, line)
+}
+
+// Tests that the population standard deviation of the supplied data is within
+// +/- eps distance of the expected standard deviation. The test fails if data
+// is empty.
+func StdDevWithin[T number](
+	t testing.TB,
+	data []T,
+	expectedStdDev float64,
+	eps float64,
+) {
+	t.Helper()
+	f, line := callerLoc()
+	if !notEmpty(t, len(data), "standard deviation", f, line) {
+		return
+	}
+	mean := sum(data) / float64(len(data))
+	variance := 0.0
+	for _, iterVal := range data {
+		d := float64(iterVal) - mean
+		variance += d * d
+	}
+	stdDev := math.Sqrt(variance / float64(len(data)))
+	aggregateWithin(
+		t, "standard deviation", stdDev, expectedStdDev, eps, len(data), f, line,
+	)
+}
+
+func sum[T number](data []T) float64 {
+	rv := 0.0
+	for _, iterVal := range data {
+		rv += float64(iterVal)
+	}
+	return rv
+}
+
+func notEmpty(t testing.TB, n int, name string, f string, line int) bool {
+	t.Helper()
+	if n == 0 {
+		FormatError(
+			t, "non-empty data", "empty data",
+			fmt.Sprintf("The %s of empty data is undefined.", name),
+			f, line,
+		)
+		return false
+	}
+	return true
+}
+
+func aggregateWithin(
+	t testing.TB,
+	name string,
+	got float64,
+	expected float64,
+	eps float64,
+	n int,
+	f string,
+	line int,
+) {
+	t.Helper()
+	if !(math.Abs(expected-got) <= eps) {
+		FormatError(
+			t, expected, got,
+			fmt.Sprintf(
+				"The %s of the data was not within the expected range of %e | Delta: %e | Len: %d",
+				name, eps, math.Abs(expected-got), n,
+			),
+			f, line,
+		)
+	}
+}
@@ -0,0 +1,194 @@
+package sbtest
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type (
+	// Scripts the stdin of an interactive program as a sequence of expect and
+	// send steps, so that prompts and REPL like tools can be tested
+	// deterministically. Steps are added with Expect, Send, and Delay and are
+	// run in order once Start is called. An expect step waits for the output
+	// of the program to contain a string before the following steps are run,
+	// so input is never sent before the program asks for it.
+	//
+	//	script := sbtest.NewStdinScript(t, time.Second).
+	//		Expect("Name: ").Send("bob").
+	//		Expect("Age: ").Send("42")
+	//	stdin, stdout := script.Start()
+	//	runPrompts(stdin, stdout)
+	//	script.Wait()
+	StdinScript struct {
+		t       testing.TB
+		timeout time.Duration
+		steps   []scriptStep
+		done    chan struct{}
+		err     string
+		stdinR  *io.PipeReader
+		stdinW  *io.PipeWriter
+
+		mu      sync.Mutex
+		changed chan struct{}
+		output  strings.Builder
+	}
+
+	scriptStep struct {
+		expect string
+		send   string
+		delay  time.Duration
+	}
+
+	// The [io.Writer] that the scripted program writes its output to.
+	scriptOutput struct {
+		s *StdinScript
+	}
+)
+
+// Creates a new empty stdin script. Each expect step fails if the expected
+// output does not appear within the supplied timeout.
+func NewStdinScript(t testing.TB, timeout time.Duration) *StdinScript {
+	return &StdinScript{
+		t:       t,
+		timeout: timeout,
+		done:    make(chan struct{}),
+		changed: make(chan struct{}),
+	}
+}
+
+// Adds a step that waits until the output of the program contains the supplied
+// string. Only output written after the previous expect step matched is
+// searched. Returns the script so that steps can be chained.
+func (s *StdinScript) Expect(output string) *StdinScript {
+	s.steps = append(s.steps, scriptStep{expect: output})
+	return s
+}
+
+// Adds a step that writes the supplied line followed by a newline to the stdin
+// of the program. Returns the script so that steps can be chained.
+func (s *StdinScript) Send(line string) *StdinScript {
+	s.steps = append(s.steps, scriptStep{send: line + "\n"})
+	return s
+}
+
+// Adds a step that waits for the supplied duration before running the next
+// step. Returns the script so that steps can be chained.
+func (s *StdinScript) Delay(d time.Duration) *StdinScript {
+	s.steps = append(s.steps, scriptStep{delay: d})
+	return s
+}
+
+// Starts running the steps of the script in a new goroutine and returns the
+// reader the program should use as its stdin and the writer the program should
+// use as its stdout. Stdin reaches EOF once all steps have run or a step has
+// failed, which lets programs that read until EOF finish.
+func (s *StdinScript) Start() (stdin io.Reader, stdout io.Writer) {
+	s.stdinR, s.stdinW = io.Pipe()
+	go s.run()
+	return s.stdinR, &scriptOutput{s: s}
+}
+
+// Waits for all steps of the script to run and fails the test if any of them
+// failed. The output of the program is shown on failure.
+func (s *StdinScript) Wait() {
+	s.t.Helper()
+	<-s.done
+	if s.err != "" {
+		f, line := callerLoc()
+		FormatError(
+			s.t, "all steps completed", s.err,
+			"The stdin script did not complete.\nOutput:\n"+s.Output(),
+			f, line,
+		)
+	}
+}
+
+// Returns everything the program has written to its stdout so far.
+func (s *StdinScript) Output() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.output.String()
+}
+
+func (s *StdinScript) run() {
+	defer close(s.done)
+	defer s.stdinW.Close()
+	searchFrom := 0
+	for i, iterStep := range s.steps {
+		switch {
+		case iterStep.expect != "":
+			idx, ok := s.waitForOutput(iterStep.expect, searchFrom)
+			if !ok {
+				s.err = fmt.Sprintf(
+					"step %d: expected output %q within %s", i, iterStep.expect, s.timeout,
+				)
+				return
+			}
+			searchFrom = idx + len(iterStep.expect)
+		case iterStep.send != "":
+			if err := s.send(iterStep.send); err != nil {
+				s.err = fmt.Sprintf(
+					"step %d: could not send %q: %v", i, iterStep.send, err,
+				)
+				return
+			}
+		default:
+			time.Sleep(iterStep.delay)
+		}
+	}
+}
+
+// Writes the supplied data to stdin, failing if the program does not read all
+// of it within the timeout.
+func (s *StdinScript) send(data string) error {
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := io.WriteString(s.stdinW, data)
+		errCh <- err
+	}()
+	timer := time.NewTimer(s.timeout)
+	defer timer.Stop()
+	select {
+	case err := <-errCh:
+		return err
+	case <-timer.C:
+		// Closing the pipe unblocks the pending write
+		s.stdinW.Close()
+		<-errCh
+		return fmt.Errorf("the program did not read stdin within %s", s.timeout)
+	}
+}
+
+// Waits until the output contains the supplied string at or after the supplied
+// offset, returning the offset it was found at.
+func (s *StdinScript) waitForOutput(expected string, from int) (int, bool) {
+	timer := time.NewTimer(s.timeout)
+	defer timer.Stop()
+	for {
+		s.mu.Lock()
+		idx, changed := strings.Index(s.output.String()[from:], expected), s.changed
+		s.mu.Unlock()
+		if idx >= 0 {
+			return from + idx, true
+		}
+		select {
+		case <-changed:
+		case <-timer.C:
+			return 0, false
+		}
+	}
+}
+
+// Implements the [io.Writer] interface.
+func (o *scriptOutput) Write(p []byte) (int, error) {
+	o.s.mu.Lock()
+	defer o.s.mu.Unlock()
+	o.s.output.Write(p)
+	close(o.s.changed)
+	o.s.changed = make(chan struct{})
+	return len(p), nil
+}
@@ -0,0 +1,116 @@
+package sbtest
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type (
+	// The calls of a stress run that failed with the same failures.
+	stressFailure struct {
+		msgs  []string
+		first int
+		count int
+	}
+)
+
+// The maximum number of distinct failures that [Stress] lists.
+const maxStressFailures = 5
+
+// Runs fn iterations times on each of the supplied number of goroutines, all
+// started at the same time so they contend as much as possible. Each call is
+// given its own [testing.TB] and a unique index from zero to
+// goroutines*iterations-1. Failures and panics are recorded instead of
+// stopping the test, since calling t.Fatal from a goroutine other than the one
+// running the test is not allowed, and once every call has finished the test
+// fails with a summary of the distinct failures and how many calls failed
+// with each.
+//
+//	sbtest.Stress(t, 8, 1000, func(t testing.TB, i int) {
+//		c.Inc()
+//		sbtest.True(t, c.Load() > 0)
+//	})
+//
+// A fatal failure only stops the call it occurred in, all other calls still
+// run. A call that skips is stopped and not counted as failed.
+func Stress(
+	t testing.TB,
+	goroutines int,
+	iterations int,
+	fn func(t testing.TB, i int),
+) {
+	t.Helper()
+	f, line := callerLoc()
+	var mu sync.Mutex
+	failures := map[string]*stressFailure{}
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for g := range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			for i := range iterations {
+				idx := g*iterations + i
+				msgs := recordFailures(t, func(t testing.TB) { fn(t, idx) })
+				if len(msgs) == 0 {
+					continue
+				}
+				key := strings.Join(msgs, "\x00")
+				mu.Lock()
+				if failure, ok := failures[key]; ok {
+					failure.count++
+					failure.first = min(failure.first, idx)
+				} else {
+					failures[key] = &stressFailure{msgs: msgs, first: idx, count: 1}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+	if len(failures) == 0 {
+		return
+	}
+
+	sorted := make([]*stressFailure, 0, len(failures))
+	failed := 0
+	for _, iterFailure := range failures {
+		sorted = append(sorted, iterFailure)
+		failed += iterFailure.count
+	}
+	slices.SortFunc(sorted, func(l *stressFailure, r *stressFailure) int {
+		if l.count != r.count {
+			return r.count - l.count
+		}
+		return l.first - r.first
+	})
+	var sb strings.Builder
+	for _, iterFailure := range sorted[:min(len(sorted), maxStressFailures)] {
+		fmt.Fprintf(
+			&sb, "\n  Count: %d | First Index: %d%s",
+			iterFailure.count, iterFailure.first,
+			strings.ReplaceAll(fmtPropertyFailures(iterFailure.msgs), "\n", "\n  "),
+		)
+	}
+	if len(sorted) > maxStressFailures {
+		fmt.Fprintf(
+			&sb, "\n  ... %d more distinct failures",
+			len(sorted)-maxStressFailures,
+		)
+	}
+	total := goroutines * iterations
+	FormatError(
+		t, 0, failed,
+		fmt.Sprintf(
+			"Calls failed while running concurrently | Goroutines: %d | "+
+				"Iterations: %d | Failed: %d/%d | Distinct Failures: %d%s",
+			goroutines, iterations, failed, total, len(sorted), sb.String(),
+		),
+		f, line,
+	)
+}
@@ -0,0 +1,37 @@
+package sbtest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStressFailures(t *testing.T) {
+	msgs := recordFailures(t, func(t testing.TB) {
+		Stress(t, 2, 4, func(t testing.TB, i int) {
+			switch {
+			case i == 0:
+				t.Skip("not counted")
+			case i == 2:
+				panic("boom")
+			case i%2 == 1:
+				Eq(t, 0, 1)
+			}
+		})
+	})
+	Eq(t, 1, len(msgs))
+	True(t, strings.Contains(
+		msgs[0],
+		"Calls failed while running concurrently | Goroutines: 2 | "+
+			"Iterations: 4 | Failed: 5/8 | Distinct Failures: 2",
+	))
+	first := strings.Index(msgs[0], "\n  Count: 4 | First Index: 1\n    ")
+	second := strings.Index(msgs[0], "\n  Count: 1 | First Index: 2\n    panic: boom")
+	True(t, first >= 0)
+	True(t, second > first)
+}
+
+func TestStressPasses(t *testing.T) {
+	Stress(t, 4, 10, func(t testing.TB, i int) {
+		True(t, i >= 0 && i < 40)
+	})
+}
@@ -0,0 +1,115 @@
+package sbtest
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type (
+	// A single case of a table driven test.
+	Case[T any] struct {
+		// The name of the case. This is used as the name of the subtest. When
+		// the cases are supplied as a map the map key is used instead and
+		// this field is ignored.
+		Name string
+		// If true the case will be run in parallel with the other parallel
+		// cases in the table.
+		Parallel bool
+		// The data for the case that will be passed to the table function.
+		Data T
+	}
+
+	// Implemented by the [testing.TB] implementations in this package that
+	// wrap the test they were created with.
+	wrappingTB interface {
+		wrapped() testing.TB
+	}
+)
+
+// The labels of the running table and contract cases keyed by the name of the
+// subtest of the case, refer to [labelCase].
+var caseLabels sync.Map
+
+// Runs each of the supplied cases as a named subtest. The cases are run in
+// order of their sorted names, which also defines each case's index. Refer to
+// [RunTableSlice] for the details of how the cases are run.
+func RunTable[T any](
+	t *testing.T,
+	cases map[string]Case[T],
+	fn func(t *testing.T, c T),
+) {
+	names := make([]string, 0, len(cases))
+	for k := range cases {
+		names = append(names, k)
+	}
+	slices.Sort(names)
+
+	ordered := make([]Case[T], len(names))
+	for i, iterName := range names {
+		ordered[i] = cases[iterName]
+		ordered[i].Name = iterName
+	}
+	RunTableSlice(t, ordered, fn)
+}
+
+// Runs each of the supplied cases as a named subtest, in order. Cases that are
+// marked as parallel are run in parallel with each other. The subtest of the
+// case is passed to fn, so the case can start its own subtests. Every failure
+// of an assertion in this package that is made with it, with one of its
+// subtests, or with a [Group], [R], or [Collector] created from either, is
+// prefixed with the name and index of the case.
+func RunTableSlice[T any](
+	t *testing.T,
+	cases []Case[T],
+	fn func(t *testing.T, c T),
+) {
+	for i, iterCase := range cases {
+		t.Run(iterCase.Name, func(st *testing.T) {
+			if iterCase.Parallel {
+				st.Parallel()
+			}
+			labelCase(st, iterCase.Name, i)
+			fn(st, iterCase.Data)
+		})
+	}
+}
+
+// Registers the label that [FormatError] prefixes the failures of the supplied
+// case subtest with. The label is removed once the subtest and its cleanups
+// have completed.
+func labelCase(t *testing.T, name string, idx int) {
+	caseLabels.Store(t.Name(), fmt.Sprintf("Case: %s | Index: %d | ", name, idx))
+	t.Cleanup(func() { caseLabels.Delete(t.Name()) })
+}
+
+// Returns the label of the table or contract case that t belongs to, or an
+// empty string if it does not belong to one. The [testing.TB] implementations
+// in this package that wrap a test, such as [Group] and [R], are unwrapped
+// first, and a subtest started by a case belongs to that case, so the nearest
+// parent of the test that is a case provides the label.
+func caseLabel(t testing.TB) string {
+	for {
+		w, ok := t.(wrappingTB)
+		if !ok {
+			break
+		}
+		t = w.wrapped()
+	}
+	st, ok := t.(*testing.T)
+	if !ok {
+		return ""
+	}
+	for name := st.Name(); ; {
+		if label, ok := caseLabels.Load(name); ok {
+			return label.(string)
+		}
+		i := strings.LastIndexByte(name, '/')
+		if i < 0 {
+			return ""
+		}
+		name = name[:i]
+	}
+}
@@ -0,0 +1,104 @@
+package sbtest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRunTableSliceLabels(t *testing.T) {
+	var subtests atomic.Int32
+	var mu sync.Mutex
+	cases := []Case[int]{
+		{Name: "a", Data: 0},
+		{Name: "b", Data: 1, Parallel: true},
+		{Name: "c", Data: 2, Parallel: true},
+	}
+	tests := []*testing.T{}
+	// The parallel cases only run once the test that runs the table returns.
+	t.Run("table", func(t *testing.T) {
+		RunTableSlice(t, cases, func(t *testing.T, c int) {
+			Eq(
+				t, fmt.Sprintf("Case: %s | Index: %d | ", cases[c].Name, c),
+				caseLabel(t),
+			)
+			t.Run("sub", func(t *testing.T) {
+				Eq(
+					t, fmt.Sprintf("Case: %s | Index: %d | ", cases[c].Name, c),
+					caseLabel(t),
+				)
+				subtests.Add(1)
+			})
+			mu.Lock()
+			tests = append(tests, t)
+			mu.Unlock()
+		})
+	})
+	Eq(t, int32(3), subtests.Load())
+	Eq(t, 3, len(tests))
+	for _, iterT := range tests {
+		Eq(t, "", caseLabel(iterT))
+	}
+}
+
+func TestRunTableOrder(t *testing.T) {
+	got := []string{}
+	RunTable(t, map[string]Case[string]{
+		"b": {Data: "b"},
+		"a": {Data: "a"},
+	}, func(t *testing.T, c string) {
+		Eq(t, fmt.Sprintf("Case: %s | Index: %d | ", c, len(got)), caseLabel(t))
+		got = append(got, c)
+	})
+	SlicesMatch(t, []string{"a", "b"}, got)
+}
+
+func TestFormatErrorCaseLabel(t *testing.T) {
+	msgs := recordFailures(t, func(t testing.TB) {
+		caseLabels.Store(t.Name(), "Case: x | Index: 4 | ")
+		defer caseLabels.Delete(t.Name())
+		Eq(t, 1, 2)
+	})
+	Eq(t, 1, len(msgs))
+	True(t, strings.Contains(msgs[0], "Case: x | Index: 4 | The supplied values"))
+}
+
+func TestCaseLabelWrappedTB(t *testing.T) {
+	RunTableSlice(t, []Case[int]{{Name: "a"}}, func(t *testing.T, c int) {
+		label := "Case: a | Index: 0 | "
+		g := NewGroup(t)
+		Eq(t, label, caseLabel(g))
+		Eq(t, label, caseLabel(NewCollector(t)))
+		RetryTest(t, 1, 0, func(r *R) {
+			Eq(r, label, caseLabel(r))
+			Eq(r, label, caseLabel(NewGroup(r)))
+		})
+
+		msgs := recordFailures(t, func(t testing.TB) {
+			g := NewGroup(t)
+			Eq(g, 1, 2)
+			g.Report()
+		})
+		Eq(t, 1, len(msgs))
+		True(t, strings.Contains(msgs[0], label+"Assertions in the group failed."))
+		True(t, strings.Contains(msgs[0], label+"The supplied values were not equal"))
+	})
+}
+
+func TestContractRunLabels(t *testing.T) {
+	contract := NewContract[*[]string]().
+		Case("first", func(t *testing.T, v *[]string) {
+			Eq(t, "Case: first | Index: 0 | ", caseLabel(t))
+			*v = append(*v, "first")
+		}).
+		Case("second", func(t *testing.T, v *[]string) {
+			Eq(t, "Case: second | Index: 1 | ", caseLabel(t))
+			Eq(t, 0, len(*v))
+		})
+	contract.Run(t, func(t *testing.T) *[]string {
+		True(t, caseLabel(t) != "")
+		return &[]string{}
+	})
+}
@@ -1,40 +1,64 @@
 // A very simple library that helps with assertions in unit tests.
+//
+// The helpers that depend on large third party libraries are kept in their
+// own packages, sbgrpc, sbotel, sbprom, sbproto, and sbws, so that those
+// libraries are only compiled into the tests which import them. The libraries
+// are still requirements of this module.
 package sbtest
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
 	"math"
 	"reflect"
 	"regexp"
-	"runtime"
+	"runtime/debug"
+	"strings"
 	"testing"
 )
 
 // Formats an error and calls the `t.Fatal` to stop any further execution of the
-// unit test. The error will have the following format:
+// unit test. Some implementations of [testing.TB], such as [Group], record the
+// error instead of stopping the test, so all assertions return after calling
+// this function. The error is formatted by the formatter set with
+// [SetFormatter]. By default the error will have the following format:
 //
 //	Error | File <file> Line #### | <message>
 //	Expected: (<type>) <value>
 //	Got:      (<type>) <value>
 func FormatError(
-	t *testing.T,
+	t testing.TB,
 	expected any,
 	got any,
 	base string,
 	file string,
 	line int,
 ) {
-	t.Fatal(fmt.Sprintf(
-		"Error | File %s Line %d | %s\nExpected: (%T) '%v'\nGot     : (%T) '%v'",
-		file, line, base, expected, expected, got, got,
-	))
+	t.Helper()
+	f := Failure{
+		Expected:  expected,
+		Got:       got,
+		Msg:       caseLabel(t) + base,
+		File:      file,
+		Line:      line,
+		Assertion: assertionName(),
+	}
+	if stackTraces.Load() {
+		f.Stack = failureStack()
+	}
+	if sourceExcerpts.Load() {
+		f.Source = sourceExcerpt(file, line, f.Assertion)
+	}
+	writeJSONFailure(t, f)
+	t.Fatal(activeFormatter().Format(f))
 }
 
 // Tests that the expected error is present in the given error.
-func ContainsError(t *testing.T, expected error, got error, msgs ...string) {
+func ContainsError(t testing.TB, expected error, got error, msgs ...string) {
+	t.Helper()
 	if !errors.Is(got, expected) {
-		_, f, line, _ := runtime.Caller(1)
+		f, line := callerLoc()
 		FormatError(
 			t,
 			expected,
@@ -42,12 +66,13 @@ func ContainsError(t *testing.T, expected error, got error, msgs ...string) {
 			"The expected error was not contained in the given error.",
 			f, line,
 		)
+		return
 	}
 	errStr := got.Error()
 	for _, iterMsg := range msgs {
 		re := regexp.MustCompile(iterMsg)
 		if !re.MatchString(errStr) {
-			_, f, line, _ := runtime.Caller(1)
+			f, line := callerLoc()
 			FormatError(
 				t,
 				expected,
@@ -57,16 +82,284 @@ func ContainsError(t *testing.T, expected error, got error, msgs ...string) {
 				),
 				f, line,
 			)
+			return
+		}
+	}
+}
+
+// Tests that all of the expected errors are present in the given error. This
+// works with multi-errors, such as those produced by [errors.Join], because
+// [errors.Is] is used to search the error tree. All of the expected errors that
+// were not found are listed on failure.
+func ContainsAllErrors(t testing.TB, got error, expected ...error) {
+	t.Helper()
+	missing := []error{}
+	for _, iterErr := range expected {
+		if !errors.Is(got, iterErr) {
+			missing = append(missing, iterErr)
+		}
+	}
+	if len(missing) > 0 {
+		f, line := callerLoc()
+		FormatError(
+			t, expected, errChain(got),
+			fmt.Sprintf(
+				"The given error did not contain all the expected errors | Missing: %v",
+				missing,
+			),
+			f, line,
+		)
+	}
+}
+
+// Tests that the given error has the expected number of leaf errors in its
+// error tree. A leaf error is an error that does not wrap any other errors.
+// This is useful for checking the number of errors that were combined with
+// [errors.Join]. A nil error has zero leaf errors.
+func ErrorCount(t testing.TB, got error, n int) {
+	t.Helper()
+	if cnt := leafErrCount(got); cnt != n {
+		f, line := callerLoc()
+		FormatError(
+			t, n, cnt,
+			fmt.Sprintf(
+				"The given error did not contain the expected number of errors | Error: %s",
+				errChain(got),
+			),
+			f, line,
+		)
+	}
+}
+
+func leafErrCount(err error) int {
+	if err == nil {
+		return 0
+	}
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		if u := e.Unwrap(); u != nil {
+			return leafErrCount(u)
+		}
+	case interface{ Unwrap() []error }:
+		cnt := 0
+		for _, u := range e.Unwrap() {
+			cnt += leafErrCount(u)
+		}
+		return cnt
+	}
+	return 1
+}
+
+// Tests that the supplied error is nil. Unlike [Nil], the full error chain is
+// printed on failure.
+func NoError(t testing.TB, err error) {
+	t.Helper()
+	if err != nil {
+		f, line := callerLoc()
+		FormatError(
+			t, nil, errChain(err),
+			"The supplied error was not nil when it was expected to be.",
+			f, line,
+		)
+	}
+}
+
+// Tests that the supplied error is nil and returns the supplied value. This
+// keeps setup code that must not fail short, as shown below. The full error
+// chain is printed on failure and the zero value of T is returned.
+//
+//	f, err := os.Open(path)
+//	r := bufio.NewReader(sbtest.Must(t, f, err))
+//
+// Note that Go does not allow a multi-value function call to be mixed with
+// other arguments, so the result of a call such as os.Open has to be assigned
+// before it can be passed to Must.
+func Must[T any](t testing.TB, v T, err error) T {
+	t.Helper()
+	if err != nil {
+		f, line := callerLoc()
+		FormatError(
+			t, nil, errChain(err),
+			"The supplied error was not nil when it was expected to be.",
+			f, line,
+		)
+		var zero T
+		return zero
+	}
+	return v
+}
+
+// Tests that the supplied error is nil and returns the two supplied values.
+// Refer to [Must] for more details.
+func Must2[T any, U any](t testing.TB, v1 T, v2 U, err error) (T, U) {
+	t.Helper()
+	if err != nil {
+		f, line := callerLoc()
+		FormatError(
+			t, nil, errChain(err),
+			"The supplied error was not nil when it was expected to be.",
+			f, line,
+		)
+		var zeroT T
+		var zeroU U
+		return zeroT, zeroU
+	}
+	return v1, v2
+}
+
+// Tests the value and error returned by a function call at once. The error
+// matches if both errors are nil or if [errors.Is] reports that gotErr matches
+// wantErr, and the value matches if it is equal to wantVal. If either does not
+// match a single failure describing both the value and the error is reported.
+// For equality rules refer to the language reference:
+// https://go.dev/ref/spec#Comparison_operators
+//
+//	v, err := strconv.Atoi("12")
+//	sbtest.ResultEq(t, 12, nil, v, err)
+func ResultEq[T comparable](
+	t testing.TB,
+	wantVal T,
+	wantErr error,
+	gotVal T,
+	gotErr error,
+) {
+	t.Helper()
+	errOk := (wantErr == nil && gotErr == nil) ||
+		(wantErr != nil && errors.Is(gotErr, wantErr))
+	valOk := wantVal == gotVal
+	if errOk && valOk {
+		return
+	}
+
+	problems := []string{}
+	if !valOk {
+		problems = append(problems, "the value did not match")
+	}
+	if !errOk {
+		problems = append(problems, "the error did not match")
+	}
+	wantErrStr, gotErrStr := "<nil>", "<nil>"
+	if wantErr != nil {
+		wantErrStr = wantErr.Error()
+	}
+	if gotErr != nil {
+		gotErrStr = errChain(gotErr)
+	}
+	f, line := callerLoc()
+	FormatError(
+		t,
+		fmt.Sprintf("%v, %s", wantVal, wantErrStr),
+		fmt.Sprintf("%v, %s", gotVal, gotErrStr),
+		fmt.Sprintf(
+			"The result of the call did not match because %s.",
+			strings.Join(problems, " and "),
+		),
+		f, line,
+	)
+}
+
+// Tests that the supplied error is not nil.
+func Error(t testing.TB, err error) {
+	t.Helper()
+	if err == nil {
+		f, line := callerLoc()
+		FormatError(
+			t, "!nil", err,
+			"The supplied error was nil when it was not expected to be.",
+			f, line,
+		)
+	}
+}
+
+// Tests that the supplied error is not nil and that the string returned from
+// its Error method contains the supplied substring. The full error chain is
+// printed on failure.
+func ErrorContains(t testing.TB, got error, substr string) {
+	t.Helper()
+	if got == nil {
+		f, line := callerLoc()
+		FormatError(
+			t, substr, got,
+			"The supplied error was nil when it was expected to contain a substring.",
+			f, line,
+		)
+		return
+	}
+	if !strings.Contains(got.Error(), substr) {
+		f, line := callerLoc()
+		FormatError(
+			t, substr, errChain(got),
+			"The supplied error did not contain the expected substring.",
+			f, line,
+		)
+	}
+}
+
+// Tests that the supplied error is not nil and that the string returned from
+// its Error method matches the supplied regex. The full error chain is printed
+// on failure.
+func ErrorMatches(t testing.TB, got error, pattern string) {
+	t.Helper()
+	if got == nil {
+		f, line := callerLoc()
+		FormatError(
+			t, pattern, got,
+			"The supplied error was nil when it was expected to match a regex.",
+			f, line,
+		)
+		return
+	}
+	re := regexp.MustCompile(pattern)
+	if !re.MatchString(got.Error()) {
+		f, line := callerLoc()
+		FormatError(
+			t, pattern, errChain(got),
+			fmt.Sprintf(
+				"The regex '%s' did not match the supplied error", pattern,
+			),
+			f, line,
+		)
+	}
+}
+
+// Returns a string representation of the entire tree of errors that are
+// wrapped by the supplied error, one error per line. Each error is formatted
+// with %+v so errors that carry extra detail, such as stack traces, will print
+// it. Both the single error and the multi-error forms of Unwrap are followed.
+func errChain(err error) string {
+	var sb strings.Builder
+	writeErrChain(&sb, err, 0)
+	return sb.String()
+}
+
+func writeErrChain(sb *strings.Builder, err error, depth int) {
+	fmt.Fprintf(
+		sb, "\n%s- (%T) %s",
+		strings.Repeat("  ", depth), err,
+		strings.ReplaceAll(fmt.Sprintf("%+v", err), "\n", `\n`),
+	)
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		if u := e.Unwrap(); u != nil {
+			writeErrChain(sb, u, depth+1)
+		}
+	case interface{ Unwrap() []error }:
+		for _, u := range e.Unwrap() {
+			if u != nil {
+				writeErrChain(sb, u, depth+1)
+			}
 		}
 	}
 }
 
 // Tests that the supplied action results in a panic. The panic is recovered so
 // all future unit tests will still run.
-func Panics(t *testing.T, action func()) {
+func Panics(t testing.TB, action func()) {
+	t.Helper()
+	f, line := callerLoc()
 	defer func() {
+		t.Helper()
 		if r := recover(); r == nil {
-			_, f, line, _ := runtime.Caller(1)
 			FormatError(
 				t,
 				"panic", "",
@@ -79,15 +372,169 @@ func Panics(t *testing.T, action func()) {
 }
 
 // Tests that the supplied action does not result in a panic. Any panic that
-// does occur is recovered so all future unit tests will still run.
-func NoPanic(t *testing.T, action func()) {
+// does occur is recovered so all future unit tests will still run. The
+// recovered value and the stack trace of the panic are included in the failure
+// output.
+func NoPanic(t testing.TB, action func()) {
+	t.Helper()
+	f, line := callerLoc()
 	defer func() {
+		t.Helper()
 		if r := recover(); r != nil {
-			_, f, line, _ := runtime.Caller(1)
 			FormatError(
 				t,
-				"", "panic",
-				"The supplied function panicked when it shouldn't have.",
+				"no panic", r,
+				fmt.Sprintf(
+					"The supplied function panicked when it shouldn't have.\n%s",
+					panicStack(),
+				),
+				f, line,
+			)
+		}
+	}()
+	action()
+}
+
+// Returns the stack trace of the current goroutine starting at the frame that
+// called panic and ending before the frames of the testing package. Frames
+// from this package and its subpackages are removed. This must be called from
+// the deferred function that recovered the panic, otherwise the frames that
+// caused the panic will not be present in the trace.
+func panicStack() string {
+	lines := strings.Split(string(debug.Stack()), "\n")
+	start := 0
+	for i, iterLine := range lines {
+		if strings.HasPrefix(iterLine, "panic(") {
+			// Skip the panic call and the line with its file and line number
+			start = i + 2
+			break
+		}
+	}
+
+	// Frames are made of two lines, the function and the file/line
+	var sb strings.Builder
+	sb.WriteString("Stack:")
+	for i := start; i+1 < len(lines); i += 2 {
+		if strings.HasPrefix(lines[i], "testing.") {
+			break
+		}
+		// The function is followed by its arguments
+		function := lines[i]
+		if j := strings.LastIndex(function, "("); j > 0 {
+			function = function[:j]
+		}
+		if isPkgFrame(function) {
+			continue
+		}
+		sb.WriteString("\n")
+		sb.WriteString(lines[i])
+		sb.WriteString("\n")
+		sb.WriteString(lines[i+1])
+	}
+	return sb.String()
+}
+
+// Tests that the supplied action results in a panic and that the recovered
+// value is equal to the expected value. Equality is determined using the same
+// rules as [reflect.DeepEqual], except that Equal methods are preferred as
+// described by [EqEqualer]. The panic is recovered so all future unit tests
+// will still run.
+func PanicsWithValue(t testing.TB, expected any, action func()) {
+	t.Helper()
+	f, line := callerLoc()
+	defer func() {
+		t.Helper()
+		r := recover()
+		if r == nil {
+			FormatError(
+				t, expected, "no panic",
+				"The supplied function did not panic when it should have.",
+				f, line,
+			)
+			return
+		}
+		if !deepEqual(expected, r) {
+			FormatError(
+				t, expected, r,
+				fmt.Sprintf(
+					"The supplied function panicked with an unexpected value.\n%s",
+					panicStack(),
+				),
+				f, line,
+			)
+		}
+	}()
+	action()
+}
+
+// Tests that the supplied action results in a panic, that the recovered value
+// is an error, and that the expected error is present in the recovered error.
+// The panic is recovered so all future unit tests will still run.
+func PanicsWithError(t testing.TB, expected error, action func()) {
+	t.Helper()
+	f, line := callerLoc()
+	defer func() {
+		t.Helper()
+		r := recover()
+		if r == nil {
+			FormatError(
+				t, expected, "no panic",
+				"The supplied function did not panic when it should have.",
+				f, line,
+			)
+			return
+		}
+		err, ok := r.(error)
+		if !ok {
+			FormatError(
+				t, expected, r,
+				fmt.Sprintf(
+					"The supplied function panicked with a value that was not an error.\n%s",
+					panicStack(),
+				),
+				f, line,
+			)
+			return
+		}
+		if !errors.Is(err, expected) {
+			FormatError(
+				t, expected, errChain(err),
+				fmt.Sprintf(
+					"The expected error was not contained in the recovered error.\n%s",
+					panicStack(),
+				),
+				f, line,
+			)
+		}
+	}()
+	action()
+}
+
+// Tests that the supplied action results in a panic and that the recovered
+// value, when formatted with %v, matches the supplied regex. The panic is
+// recovered so all future unit tests will still run.
+func PanicsMatching(t testing.TB, pattern string, action func()) {
+	t.Helper()
+	f, line := callerLoc()
+	re := regexp.MustCompile(pattern)
+	defer func() {
+		t.Helper()
+		r := recover()
+		if r == nil {
+			FormatError(
+				t, pattern, "no panic",
+				"The supplied function did not panic when it should have.",
+				f, line,
+			)
+			return
+		}
+		if msg := fmt.Sprintf("%v", r); !re.MatchString(msg) {
+			FormatError(
+				t, pattern, msg,
+				fmt.Sprintf(
+					"The regex '%s' did not match the recovered panic value\n%s",
+					pattern, panicStack(),
+				),
 				f, line,
 			)
 		}
@@ -97,9 +544,10 @@ func NoPanic(t *testing.T, action func()) {
 
 // Tests that the supplied values are equal. For equality rules refer to the
 // language reference: https://go.dev/ref/spec#Comparison_operators
-func Eq[T comparable](t *testing.T, expected T, got T) {
+func Eq[T comparable](t testing.TB, expected T, got T) {
+	t.Helper()
 	if expected != got {
-		_, f, line, _ := runtime.Caller(1)
+		f, line := callerLoc()
 		FormatError(
 			t, expected, got,
 			"The supplied values were not equal but were expected to be.",
@@ -111,13 +559,14 @@ func Eq[T comparable](t *testing.T, expected T, got T) {
 // Tests that the expected value is present in the supplied slice. For equality
 // rules refer to the language reference:
 // https://go.dev/ref/spec#Comparison_operators
-func EqOneOf[T comparable](t *testing.T, expected T, data []T) {
+func EqOneOf[T comparable](t testing.TB, expected T, data []T) {
+	t.Helper()
 	for _, rVal := range data {
 		if expected == rVal {
 			return
 		}
 	}
-	_, f, line, _ := runtime.Caller(1)
+	f, line := callerLoc()
 	FormatError(
 		t, expected, data,
 		"The supplied value is not in the supplied slice.",
@@ -125,10 +574,129 @@ func EqOneOf[T comparable](t *testing.T, expected T, data []T) {
 	)
 }
 
+// Tests that the supplied values are pointers of the same type that reference
+// the same object. Note that this is not a value comparison, two pointers to
+// distinct but equal values will fail this test.
+func Same(t testing.TB, expected any, got any) {
+	t.Helper()
+	if !samePntr(expected, got) {
+		f, line := callerLoc()
+		FormatError(
+			t, fmtPntr(expected), fmtPntr(got),
+			"The supplied values did not reference the same object but were expected to.",
+			f, line,
+		)
+	}
+}
+
+// Tests that the supplied values do not reference the same object. Values that
+// are not pointers, or are pointers of different types, never reference the
+// same object.
+func NotSame(t testing.TB, expected any, got any) {
+	t.Helper()
+	if samePntr(expected, got) {
+		f, line := callerLoc()
+		FormatError(
+			t, fmtPntr(expected), fmtPntr(got),
+			"The supplied values referenced the same object but were expected to not.",
+			f, line,
+		)
+	}
+}
+
+func samePntr(l any, r any) bool {
+	lv := reflect.ValueOf(l)
+	rv := reflect.ValueOf(r)
+	if lv.Kind() != reflect.Pointer || rv.Kind() != reflect.Pointer {
+		return false
+	}
+	if lv.Type() != rv.Type() {
+		return false
+	}
+	return lv.Pointer() == rv.Pointer()
+}
+
+func fmtPntr(v any) string {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer {
+		return fmt.Sprintf("%p", v)
+	}
+	return fmt.Sprintf("not a pointer: %v", v)
+}
+
+// Tests that got is not nil and that the value it points to is equal to the
+// expected value. For equality rules refer to the language reference:
+// https://go.dev/ref/spec#Comparison_operators
+func EqPtr[T comparable](t testing.TB, expected T, got *T) {
+	t.Helper()
+	if got == nil {
+		f, line := callerLoc()
+		FormatError(
+			t, expected, nil,
+			"The supplied pointer was nil but was expected to point to a value.",
+			f, line,
+		)
+		return
+	}
+	if expected != *got {
+		f, line := callerLoc()
+		FormatError(
+			t, expected, *got,
+			"The value pointed to was not equal to the expected value.",
+			f, line,
+		)
+	}
+}
+
+// Tests that the supplied slices of pointers are the same length and that the
+// values pointed to at each index are equal. Two nil pointers are equal and a
+// nil pointer is never equal to a non-nil pointer. For equality rules refer to
+// the language reference: https://go.dev/ref/spec#Comparison_operators
+func EqDerefSlices[T comparable](t testing.TB, expected []*T, got []*T) {
+	t.Helper()
+	// Comparing the pointed to values as interfaces treats nil pointers as
+	// equal to each other and to nothing else
+	deref := func(s []*T) []any {
+		rv := make([]any, len(s))
+		for i, iterPtr := range s {
+			if iterPtr != nil {
+				rv[i] = *iterPtr
+			}
+		}
+		return rv
+	}
+	e, g := deref(expected), deref(got)
+	eq := func(l any, r any) bool { return l == r }
+
+	if len(e) != len(g) {
+		f, line := callerLoc()
+		FormatError(
+			t, len(e), len(g),
+			"Slices do not match in length.\nDiff:"+sliceDiff(e, g, eq),
+			f, line,
+		)
+		return
+	}
+	for i := range e {
+		if e[i] != g[i] {
+			f, line := callerLoc()
+			FormatError(
+				t, e[i], g[i],
+				fmt.Sprintf(
+					"Values do not match | Index: %d\nDiff:%s",
+					i, sliceDiff(e, g, eq),
+				),
+				f, line,
+			)
+			return
+		}
+	}
+}
+
 // Tests that the given float is within +/- eps distance of the expected float.
-func EqFloat[T ~float32 | float64](t *testing.T, expected T, got T, eps T) {
+func EqFloat[T ~float32 | float64](t testing.TB, expected T, got T, eps T) {
+	t.Helper()
 	if math.Abs(float64(expected-got)) > float64(eps) {
-		_, f, line, _ := runtime.Caller(1)
+		f, line := callerLoc()
 		FormatError(
 			t, expected, got,
 			fmt.Sprintf(
@@ -140,11 +708,62 @@ func EqFloat[T ~float32 | float64](t *testing.T, expected T, got T, eps T) {
 	}
 }
 
+// Tests that the supplied slices are the same length and that every value in
+// got is within +/- eps distance of the value at the same index in expected.
+// On failure the index, delta, and tolerance of the element with the largest
+// delta are reported along with an index aligned diff of the slices. NaN
+// values are never considered equal.
+func SlicesEqFloat[T ~float32 | float64](
+	t testing.TB,
+	expected []T,
+	got []T,
+	eps T,
+) {
+	t.Helper()
+	f, line := callerLoc()
+	within := func(l T, r T) bool {
+		return math.Abs(float64(l-r)) <= float64(eps)
+	}
+	if len(expected) != len(got) {
+		FormatError(
+			t, len(expected), len(got),
+			"Slices do not match in length.\nDiff:"+sliceDiff(expected, got, within),
+			f, line,
+		)
+		return
+	}
+
+	worst, worstDelta, failed := -1, 0.0, 0
+	for i := range expected {
+		if within(expected[i], got[i]) {
+			continue
+		}
+		failed++
+		delta := math.Abs(float64(expected[i] - got[i]))
+		if worst < 0 || (!math.IsNaN(worstDelta) &&
+			(math.IsNaN(delta) || delta > worstDelta)) {
+			worst, worstDelta = i, delta
+		}
+	}
+	if worst < 0 {
+		return
+	}
+	FormatError(
+		t, expected[worst], got[worst],
+		fmt.Sprintf(
+			"Values were not within the tolerance | Worst Index: %d | Delta: %e | Tolerance: %e | Failing Values: %d\nDiff:%s",
+			worst, worstDelta, eps, failed, sliceDiff(expected, got, within),
+		),
+		f, line,
+	)
+}
+
 // Tests that the given value is equal to the expected value using the supplied
 // comparison function to determine equality.
-func EqFunc[T any](t *testing.T, expected T, got T, cmp func(l T, r T) bool) {
+func EqFunc[T any](t testing.TB, expected T, got T, cmp func(l T, r T) bool) {
+	t.Helper()
 	if !cmp(expected, got) {
-		_, f, line, _ := runtime.Caller(1)
+		f, line := callerLoc()
 		FormatError(
 			t, expected, got,
 			"The supplied values were not equal as defined by the supplied comparison function but were expected to be.",
@@ -155,9 +774,10 @@ func EqFunc[T any](t *testing.T, expected T, got T, cmp func(l T, r T) bool) {
 
 // Tests that the supplied values are not equal. For equality rules refer to the
 // language reference: https://go.dev/ref/spec#Comparison_operators
-func Neq[T comparable](t *testing.T, expected any, got any) {
+func Neq[T comparable](t testing.TB, expected any, got any) {
+	t.Helper()
 	if expected == got {
-		_, f, line, _ := runtime.Caller(1)
+		f, line := callerLoc()
 		FormatError(
 			t, expected, got,
 			"The supplied values were equal but were expected to not be.",
@@ -170,9 +790,10 @@ func Neq[T comparable](t *testing.T, expected any, got any) {
 // expressions that evaluate to a boolean. This should not be used for equality
 // comparisons such as `True(t, 5==5)`. For equality comparisons refer to one
 // of the Eq* functions defined in this file.
-func True(t *testing.T, v bool) {
+func True(t testing.TB, v bool) {
+	t.Helper()
 	if v != true {
-		_, f, line, _ := runtime.Caller(1)
+		f, line := callerLoc()
 		FormatError(
 			t, true, v,
 			"The supplied value was not true when it was expected to be.",
@@ -185,9 +806,10 @@ func True(t *testing.T, v bool) {
 // expressions that evaluate to a boolean. This should not be used for equality
 // comparisons such as `False(t, 6!=5)`. For equality comparisons refer to one
 // of the Eq* functions defined in this file.
-func False(t *testing.T, v bool) {
+func False(t testing.TB, v bool) {
+	t.Helper()
 	if v != false {
-		_, f, line, _ := runtime.Caller(1)
+		f, line := callerLoc()
 		FormatError(
 			t, false, v,
 			"The supplied value was not false when it was expected to be.",
@@ -198,7 +820,8 @@ func False(t *testing.T, v bool) {
 
 // Tests that the supplied value is nil. `nil` slices, maps, pointers, and
 // interfaces are considered to be nil and will pass this test.
-func Nil(t *testing.T, v any) {
+func Nil(t testing.TB, v any) {
+	t.Helper()
 	// The actual value is nil
 	if v == nil {
 		return
@@ -216,7 +839,7 @@ func Nil(t *testing.T, v any) {
 		return
 	}
 
-	_, f, line, _ := runtime.Caller(1)
+	f, line := callerLoc()
 	FormatError(
 		t, nil, v,
 		"The supplied value was not nil when it was expected to be.",
@@ -226,7 +849,8 @@ func Nil(t *testing.T, v any) {
 
 // Tests that the supplied value is not nil. `nil` slices, maps, pointers, and
 // interfaces are considered to be nil and will fail this test.
-func NotNil(t *testing.T, v any) {
+func NotNil(t testing.TB, v any) {
+	t.Helper()
 	var rv reflect.Value
 	var tv reflect.Type
 
@@ -250,7 +874,7 @@ func NotNil(t *testing.T, v any) {
 	return
 
 fail:
-	_, f, line, _ := runtime.Caller(1)
+	f, line := callerLoc()
 	FormatError(
 		t, "!nil", v,
 		"The supplied value was nil when it was not expected to be.",
@@ -258,26 +882,111 @@ fail:
 	)
 }
 
+// Tests that the dynamic type of the supplied value is T and returns the value
+// converted to T. If T is an interface type then the dynamic type of the value
+// must implement T.
+func IsType[T any](t testing.TB, v any) T {
+	t.Helper()
+	res, ok := v.(T)
+	if !ok {
+		f, line := callerLoc()
+		FormatError(
+			t,
+			reflect.TypeFor[T]().String(), fmt.Sprintf("%T", v),
+			"The supplied value was not of the expected type.",
+			f, line,
+		)
+	}
+	return res
+}
+
+// Tests that the dynamic type of the supplied value is not T. If T is an
+// interface type then the dynamic type of the value must not implement T.
+func NotType[T any](t testing.TB, v any) {
+	t.Helper()
+	if _, ok := v.(T); ok {
+		f, line := callerLoc()
+		FormatError(
+			t,
+			"!"+reflect.TypeFor[T]().String(), fmt.Sprintf("%T", v),
+			"The supplied value was of a type it was not expected to be.",
+			f, line,
+		)
+	}
+}
+
+// Tests that the dynamic type of the supplied value implements the interface
+// pointed to by iface. The iface argument should be a nil pointer to the
+// interface, as shown below. Passing anything other than a pointer to an
+// interface will cause a panic.
+//
+//	Implements(t, (*io.Reader)(nil), v)
+func Implements(t testing.TB, iface any, v any) {
+	t.Helper()
+	it := reflect.TypeOf(iface)
+	if it == nil ||
+		it.Kind() != reflect.Pointer ||
+		it.Elem().Kind() != reflect.Interface {
+		panic(fmt.Sprintf(
+			"sbtest: Implements expected a pointer to an interface, got %T",
+			iface,
+		))
+	}
+	it = it.Elem()
+	vt := reflect.TypeOf(v)
+	if vt == nil || !vt.Implements(it) {
+		f, line := callerLoc()
+		FormatError(
+			t,
+			it.String(), fmt.Sprintf("%T", v),
+			"The supplied value did not implement the expected interface.",
+			f, line,
+		)
+	}
+}
+
 // Tests that the supplied slices match. In order for the slices to match they
 // must be the same length and values in the same index must compare equal. For
 // equality rules refer to the language reference:
 // https://go.dev/ref/spec#Comparison_operators
-func SlicesMatch[T comparable](t *testing.T, expected []T, got []T) {
-	_, f, line, _ := runtime.Caller(1)
+//
+// On failure an index aligned diff of the whole slices is shown. Ranges of
+// indexes that match are collapsed and each index that does not match is
+// listed with both values.
+func SlicesMatch[T comparable](t testing.TB, expected []T, got []T) {
+	t.Helper()
+	f, line := callerLoc()
+	slicesMatch(t, expected, got, f, line)
+}
+
+func slicesMatch[T comparable](
+	t testing.TB,
+	expected []T,
+	got []T,
+	f string,
+	line int,
+) {
+	t.Helper()
+	eq := func(l T, r T) bool { return l == r }
 	if len(expected) != len(got) {
 		FormatError(
 			t, len(expected), len(got),
-			"Slices do not match in length.",
+			"Slices do not match in length.\nDiff:"+sliceDiff(expected, got, eq),
 			f, line,
 		)
+		return
 	}
 	for i := 0; i < len(expected); i++ {
 		if expected[i] != got[i] {
 			FormatError(
 				t, expected[i], got[i],
-				fmt.Sprintf("Values do not match | Index: %d", i),
+				fmt.Sprintf(
+					"Values do not match | Index: %d\nDiff:%s",
+					i, sliceDiff(expected, got, eq),
+				),
 				f, line,
 			)
+			return
 		}
 	}
 }
@@ -285,60 +994,170 @@ func SlicesMatch[T comparable](t *testing.T, expected []T, got []T) {
 // Tests that the supplied slices match in length and content but not in order.
 // For equality rules refer to the language reference:
 // https://go.dev/ref/spec#Comparison_operators
-func SlicesMatchUnordered[T comparable](t *testing.T, expected []T, got []T) {
-	_, f, line, _ := runtime.Caller(1)
+//
+// The slices are compared by counting the occurrences of each value, so this
+// runs in linear time. On failure each value that was missing from got, or
+// present in got more times than expected, is listed with the difference in
+// its count.
+func SlicesMatchUnordered[T comparable](t testing.TB, expected []T, got []T) {
+	t.Helper()
+	f, line := callerLoc()
+	slicesMatchUnordered(t, expected, got, f, line)
+}
+
+func slicesMatchUnordered[T comparable](
+	t testing.TB,
+	expected []T,
+	got []T,
+	f string,
+	line int,
+) {
+	t.Helper()
+	// Positive counts are values expected more times than they were present,
+	// negative counts are values present more times than they were expected.
+	counts := make(map[T]int, len(expected))
+	order := []T{}
+	for _, iterVal := range expected {
+		if _, ok := counts[iterVal]; !ok {
+			order = append(order, iterVal)
+		}
+		counts[iterVal]++
+	}
+	for _, iterVal := range got {
+		if _, ok := counts[iterVal]; !ok {
+			order = append(order, iterVal)
+		}
+		counts[iterVal]--
+	}
+
+	var sb strings.Builder
+	for _, iterVal := range order {
+		if c := counts[iterVal]; c > 0 {
+			fmt.Fprintf(&sb, "\n  Value: %v | Missing: %d", iterVal, c)
+		} else if c < 0 {
+			fmt.Fprintf(&sb, "\n  Value: %v | Surplus: %d", iterVal, -c)
+		}
+	}
+	if sb.Len() == 0 {
+		return
+	}
+
+	if len(expected) != len(got) {
+		FormatError(
+			t, len(expected), len(got),
+			"Slices do not match in length.\nDifferences:"+sb.String(),
+			f, line,
+		)
+		return
+	}
+	FormatError(
+		t, expected, got,
+		"The slices were not found to have equivalent elements.\nDifferences:"+sb.String(),
+		f, line,
+	)
+}
+
+// Tests that the supplied slices match in length and content but not in order,
+// using the supplied function to compare values. This allows slices of values
+// that are not comparable, such as structs containing slices or maps, to be
+// compared.
+//
+// Values are paired using a maximum bipartite matching, so duplicate values
+// and comparison functions that are not transitive never cause a false
+// failure. This always calls eq n^2 times, once for every pair of values, and
+// takes O(n^3) time in the worst case, so [SlicesMatchUnordered] should be
+// preferred for comparable values. On failure the indexes of all values that
+// could not be paired are listed.
+func SlicesMatchUnorderedFunc[T any](
+	t testing.TB,
+	expected []T,
+	got []T,
+	eq func(l T, r T) bool,
+) {
+	t.Helper()
 	if len(expected) != len(got) {
+		f, line := callerLoc()
 		FormatError(
 			t, len(expected), len(got),
 			"Slices do not match in length.",
 			f, line,
 		)
+		return
 	}
 
-	usedIndexes := map[int]struct{}{}
-	for i := 0; i < len(expected); i++ {
-		found := false
-		for j := 0; j < len(got) && !found; j++ {
-			// The values at indexes i and j matched
-			if expected[i] == got[j] {
-				if _, ok := usedIndexes[j]; !ok {
-					found = true
-					usedIndexes[j] = struct{}{}
-				}
+	// The adjacency lists of the bipartite graph, edges[i] holds the indexes
+	// of got that are equal to expected[i]
+	edges := make([][]int, len(expected))
+	for i := range expected {
+		for j := range got {
+			if eq(expected[i], got[j]) {
+				edges[i] = append(edges[i], j)
 			}
 		}
-		if !found {
-			FormatError(
-				t, expected, got[i],
-				fmt.Sprintf("Slice value was not accounted for | Index: %d", i),
-				f, line,
-			)
+	}
+
+	// matchedBy[j] is the index of expected that got[j] is paired with, or -1
+	matchedBy := make([]int, len(got))
+	for j := range matchedBy {
+		matchedBy[j] = -1
+	}
+	var augment func(i int, visited []bool) bool
+	augment = func(i int, visited []bool) bool {
+		for _, j := range edges[i] {
+			if visited[j] {
+				continue
+			}
+			visited[j] = true
+			if matchedBy[j] < 0 || augment(matchedBy[j], visited) {
+				matchedBy[j] = i
+				return true
+			}
 		}
+		return false
+	}
+	unmatchedExpected := []int{}
+	for i := range expected {
+		if !augment(i, make([]bool, len(got))) {
+			unmatchedExpected = append(unmatchedExpected, i)
+		}
+	}
+	if len(unmatchedExpected) == 0 {
+		return
 	}
 
-	if len(usedIndexes) != len(expected) {
-		FormatError(
-			t, expected, got,
-			"The slices were not found to have equivalent elements.",
-			f, line,
-		)
+	var sb strings.Builder
+	for _, i := range unmatchedExpected {
+		fmt.Fprintf(&sb, "\n  Expected Index: %d | Value: %v", i, expected[i])
 	}
+	for j, i := range matchedBy {
+		if i < 0 {
+			fmt.Fprintf(&sb, "\n  Got Index: %d | Value: %v", j, got[j])
+		}
+	}
+	f, line := callerLoc()
+	FormatError(
+		t, expected, got,
+		"The slices were not found to have equivalent elements.\nUnmatched:"+sb.String(),
+		f, line,
+	)
 }
 
 // Tests that the supplied maps match in length and content. For equality rules
 // refer to the language reference: https://go.dev/ref/spec#Comparison_operators
 func MapsMatch[K comparable, V any](
-	t *testing.T,
+	t testing.TB,
 	expected map[K]V,
 	got map[K]V,
 ) {
-	_, f, line, _ := runtime.Caller(1)
+	t.Helper()
+	f, line := callerLoc()
 	if len(expected) != len(got) {
 		FormatError(
 			t, len(expected), len(got),
 			"Maps do not match in length.",
 			f, line,
 		)
+		return
 	}
 
 	for k, v := range expected {
@@ -349,6 +1168,7 @@ func MapsMatch[K comparable, V any](
 				fmt.Sprintf("A key was not found | Key: %v", k),
 				f, line,
 			)
+			return
 		}
 		if any(gotV) != any(v) {
 			FormatError(
@@ -356,6 +1176,432 @@ func MapsMatch[K comparable, V any](
 				"The values stored in the map did not match.",
 				f, line,
 			)
+			return
 		}
 	}
 }
+
+// Tests that every value in the supplied slice satisfies the predicate. All
+// indexes and values that do not satisfy it are listed on failure.
+func All[T any](t testing.TB, data []T, pred func(v T) bool) {
+	t.Helper()
+	if violations := predViolations(data, pred, false); violations != "" {
+		f, line := callerLoc()
+		FormatError(
+			t, "all values satisfy the predicate", "some values did not",
+			"Values did not satisfy the predicate.\nViolations:"+violations,
+			f, line,
+		)
+	}
+}
+
+// Tests that at least one value in the supplied slice satisfies the predicate.
+// The slice is shown on failure, truncated if it is long.
+func Any[T any](t testing.TB, data []T, pred func(v T) bool) {
+	t.Helper()
+	for _, iterVal := range data {
+		if pred(iterVal) {
+			return
+		}
+	}
+	f, line := callerLoc()
+	FormatError(
+		t, "a value satisfies the predicate", "no values did",
+		fmt.Sprintf(
+			"No value satisfied the predicate | Data: %s", fmtTruncated(data),
+		),
+		f, line,
+	)
+}
+
+// Tests that no value in the supplied slice satisfies the predicate. All
+// indexes and values that satisfy it are listed on failure.
+func None[T any](t testing.TB, data []T, pred func(v T) bool) {
+	t.Helper()
+	if violations := predViolations(data, pred, true); violations != "" {
+		f, line := callerLoc()
+		FormatError(
+			t, "no values satisfy the predicate", "some values did",
+			"Values satisfied the predicate.\nViolations:"+violations,
+			f, line,
+		)
+	}
+}
+
+// Returns a list of the indexes and values in data for which the predicate
+// returns the supplied result, or an empty string if there are none.
+func predViolations[T any](data []T, pred func(v T) bool, result bool) string {
+	var sb strings.Builder
+	for i, iterVal := range data {
+		if pred(iterVal) == result {
+			fmt.Fprintf(&sb, "\n  Index: %d | Value: %v", i, iterVal)
+		}
+	}
+	return sb.String()
+}
+
+// The maximum number of values that fmtTruncated prints.
+const maxTruncatedLen = 20
+
+// Tests that the smallest value in the supplied slice is equal to the expected
+// value. The test fails if the slice is empty. On failure the slice is shown,
+// truncated if it is long.
+func MinEq[T cmp.Ordered](t testing.TB, expected T, data []T) {
+	t.Helper()
+	f, line := callerLoc()
+	extremeEq(
+		t, "smallest", expected, data,
+		func(l T, r T) bool { return l < r },
+		f, line,
+	)
+}
+
+// Tests that the largest value in the supplied slice is equal to the expected
+// value. The test fails if the slice is empty. On failure the slice is shown,
+// truncated if it is long.
+func MaxEq[T cmp.Ordered](t testing.TB, expected T, data []T) {
+	t.Helper()
+	f, line := callerLoc()
+	extremeEq(
+		t, "largest", expected, data,
+		func(l T, r T) bool { return l > r },
+		f, line,
+	)
+}
+
+func extremeEq[T cmp.Ordered](
+	t testing.TB,
+	name string,
+	expected T,
+	data []T,
+	better func(l T, r T) bool,
+	f string,
+	line int,
+) {
+	t.Helper()
+	if len(data) == 0 {
+		FormatError(
+			t, expected, "empty slice",
+			fmt.Sprintf("The %s value of an empty slice is undefined.", name),
+			f, line,
+		)
+		return
+	}
+	idx := 0
+	for i := 1; i < len(data); i++ {
+		if better(data[i], data[idx]) {
+			idx = i
+		}
+	}
+	if data[idx] != expected {
+		FormatError(
+			t, expected, data[idx],
+			fmt.Sprintf(
+				"The %s value in the slice was not the expected value | Index: %d | Data: %s",
+				name, idx, fmtTruncated(data),
+			),
+			f, line,
+		)
+	}
+}
+
+// Returns the supplied slice printed with at most maxTruncatedLen values.
+func fmtTruncated[T any](data []T) string {
+	if len(data) <= maxTruncatedLen {
+		return fmt.Sprintf("%v", data)
+	}
+	s := fmt.Sprintf("%v", data[:maxTruncatedLen])
+	return fmt.Sprintf(
+		"%s ... %d more]", s[:len(s)-1], len(data)-maxTruncatedLen,
+	)
+}
+
+// Tests that the supplied slice is strictly increasing. Every value in the
+// slice must be greater than the value that comes before it.
+func StrictlyIncreasing[T cmp.Ordered](t testing.TB, data []T) {
+	t.Helper()
+	monotonic(
+		t, data, "<",
+		func(l T, r T) bool { return l < r },
+		"The supplied slice was not strictly increasing",
+	)
+}
+
+// Tests that the supplied slice is non-decreasing. Every value in the slice
+// must be greater than or equal to the value that comes before it.
+func NonDecreasing[T cmp.Ordered](t testing.TB, data []T) {
+	t.Helper()
+	monotonic(
+		t, data, "<=",
+		func(l T, r T) bool { return l <= r },
+		"The supplied slice was not non-decreasing",
+	)
+}
+
+// Tests that the supplied slice is strictly decreasing. Every value in the
+// slice must be less than the value that comes before it.
+func StrictlyDecreasing[T cmp.Ordered](t testing.TB, data []T) {
+	t.Helper()
+	monotonic(
+		t, data, ">",
+		func(l T, r T) bool { return l > r },
+		"The supplied slice was not strictly decreasing",
+	)
+}
+
+func monotonic[T cmp.Ordered](
+	t testing.TB,
+	data []T,
+	op string,
+	ok func(l T, r T) bool,
+	base string,
+) {
+	t.Helper()
+	for i := 1; i < len(data); i++ {
+		if !ok(data[i-1], data[i]) {
+			f, line := callerLoc()
+			FormatError(
+				t,
+				fmt.Sprintf("%v %s %v", data[i-1], op, data[i]),
+				fmt.Sprintf("%v !%s %v", data[i-1], op, data[i]),
+				fmt.Sprintf("%s | Indexes: %d, %d", base, i-1, i),
+				f, line,
+			)
+			return
+		}
+	}
+}
+
+// Tests that the values in needle appear in haystack in the same order, though
+// not necessarily next to each other. For example, the events A then C appear
+// in order in the log [A, B, C]. On failure the values of needle that were
+// found are shown along with the first value that was not found and the range
+// of haystack that was searched for it.
+func ContainsSubsequence[T comparable](t testing.TB, haystack []T, needle []T) {
+	t.Helper()
+	matched := 0
+	lastMatch := -1
+	for i := 0; i < len(haystack) && matched < len(needle); i++ {
+		if haystack[i] == needle[matched] {
+			matched++
+			lastMatch = i
+		}
+	}
+	if matched == len(needle) {
+		return
+	}
+	f, line := callerLoc()
+	FormatError(
+		t, needle, haystack,
+		fmt.Sprintf(
+			"The haystack did not contain the needle as a subsequence | Found: %v | Missing: %v | Searched: [%d:%d] %v",
+			needle[:matched], needle[matched],
+			lastMatch+1, len(haystack), haystack[lastMatch+1:],
+		),
+		f, line,
+	)
+}
+
+// Tests that the values in needle appear in haystack next to each other and in
+// the same order. For example, the events B then C appear as a subslice of the
+// log [A, B, C]. On failure the window of haystack that matched the longest
+// prefix of needle is shown.
+func ContainsSubslice[T comparable](t testing.TB, haystack []T, needle []T) {
+	t.Helper()
+	if len(needle) == 0 {
+		return
+	}
+	bestStart, bestLen := 0, 0
+	for i := range haystack {
+		n := 0
+		for n < len(needle) && i+n < len(haystack) && haystack[i+n] == needle[n] {
+			n++
+		}
+		if n == len(needle) {
+			return
+		}
+		if n > bestLen {
+			bestStart, bestLen = i, n
+		}
+	}
+	end := min(bestStart+len(needle), len(haystack))
+	f, line := callerLoc()
+	FormatError(
+		t, needle, haystack,
+		fmt.Sprintf(
+			"The haystack did not contain the needle as a subslice | Longest Prefix Match: %d | Window: [%d:%d] %v",
+			bestLen, bestStart, end, haystack[bestStart:end],
+		),
+		f, line,
+	)
+}
+
+// Tests that the supplied value occurs in data exactly the expected number of
+// times. For equality rules refer to the language reference:
+// https://go.dev/ref/spec#Comparison_operators
+func CountEq[T comparable](t testing.TB, expectedCount int, data []T, value T) {
+	t.Helper()
+	f, line := callerLoc()
+	countFunc(
+		t, expectedCount, data, func(v T) bool { return v == value },
+		fmt.Sprintf("Value: %v", value), f, line,
+	)
+}
+
+// Tests that exactly the expected number of values in data satisfy the
+// supplied predicate.
+func CountFunc[T any](
+	t testing.TB,
+	expectedCount int,
+	data []T,
+	pred func(v T) bool,
+) {
+	t.Helper()
+	f, line := callerLoc()
+	countFunc(t, expectedCount, data, pred, "", f, line)
+}
+
+func countFunc[T any](
+	t testing.TB,
+	expectedCount int,
+	data []T,
+	pred func(v T) bool,
+	desc string,
+	f string,
+	line int,
+) {
+	t.Helper()
+	matches := []int{}
+	for i, iterVal := range data {
+		if pred(iterVal) {
+			matches = append(matches, i)
+		}
+	}
+	if len(matches) == expectedCount {
+		return
+	}
+	if desc != "" {
+		desc = " | " + desc
+	}
+	FormatError(
+		t, expectedCount, len(matches),
+		fmt.Sprintf(
+			"The number of matching values was not the expected count%s | Matching Indexes: %v",
+			desc, matches,
+		),
+		f, line,
+	)
+}
+
+// Tests that the supplied two dimensional slices match. In order for the slices
+// to match every inner slice must be the same length and values at the same
+// coordinate must be equal. Values are compared in the same way as
+// [MatchNested]. On failure the full coordinate of the first mismatch is
+// reported.
+func SlicesMatch2D[T comparable](t testing.TB, expected [][]T, got [][]T) {
+	t.Helper()
+	f, line := callerLoc()
+	matchNested(t, reflect.ValueOf(expected), reflect.ValueOf(got), f, line)
+}
+
+// Tests that the supplied nested slices or arrays match to any depth, such as
+// [][][]T. Values that are not slices or arrays are compared using the same
+// rules as [reflect.DeepEqual], except that Equal methods are preferred as
+// described by [EqEqualer]. On failure the full coordinate of the first
+// mismatch, for example [3][7], is reported.
+func MatchNested(t testing.TB, expected any, got any) {
+	t.Helper()
+	f, line := callerLoc()
+	if reflect.TypeOf(expected) != reflect.TypeOf(got) {
+		FormatError(
+			t, fmt.Sprintf("%T", expected), fmt.Sprintf("%T", got),
+			"The supplied values were not the same type.",
+			f, line,
+		)
+		return
+	}
+	matchNested(t, reflect.ValueOf(expected), reflect.ValueOf(got), f, line)
+}
+
+func matchNested(
+	t testing.TB,
+	expected reflect.Value,
+	got reflect.Value,
+	f string,
+	line int,
+) {
+	t.Helper()
+	coord, e, g, lenMismatch, ok := firstNestedMismatch(expected, got, "")
+	if ok {
+		return
+	}
+	if lenMismatch {
+		FormatError(
+			t, e, g,
+			fmt.Sprintf("Slices do not match in length | Coordinate: %s", coord),
+			f, line,
+		)
+		return
+	}
+	FormatError(
+		t, e, g,
+		fmt.Sprintf("Values do not match | Coordinate: %s", coord),
+		f, line,
+	)
+}
+
+// Returns the coordinate and values of the first mismatch between the supplied
+// values. If the mismatch is a difference in length the returned values are
+// the lengths. The coordinate is empty when the top level values differ.
+func firstNestedMismatch(
+	expected reflect.Value,
+	got reflect.Value,
+	coord string,
+) (string, any, any, bool, bool) {
+	if !expected.IsValid() || !got.IsValid() {
+		if expected.IsValid() == got.IsValid() {
+			return "", nil, nil, false, true
+		}
+		return coord, fmtReflect(expected), fmtReflect(got), false, false
+	}
+	if expected.Kind() == reflect.Interface && got.Kind() == reflect.Interface {
+		return firstNestedMismatch(expected.Elem(), got.Elem(), coord)
+	}
+	if expected.Type() != got.Type() {
+		return coord, expected.Interface(), got.Interface(), false, false
+	}
+	switch expected.Kind() {
+	case reflect.Slice, reflect.Array:
+		if expected.Len() != got.Len() {
+			return coord, expected.Len(), got.Len(), true, false
+		}
+		for i := range expected.Len() {
+			c, e, g, lenMismatch, ok := firstNestedMismatch(
+				expected.Index(i), got.Index(i), fmt.Sprintf("%s[%d]", coord, i),
+			)
+			if !ok {
+				return c, e, g, lenMismatch, false
+			}
+		}
+		return "", nil, nil, false, true
+	}
+
+	e, g := expected.Interface(), got.Interface()
+	if deepEqual(e, g) {
+		return "", nil, nil, false, true
+	}
+	return coord, e, g, false, false
+}
+
+// Returns the value held by the supplied reflect value so that it can be
+// passed to [FormatError]. Values that cannot be converted to an interface,
+// such as unexported struct fields, are returned in their printed form.
+func fmtReflect(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if !v.CanInterface() {
+		return fmt.Sprintf("%v", v)
+	}
+	return v.Interface()
+}
@@ -0,0 +1,59 @@
+package sbtest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSlicesMatchUnorderedFuncDuplicates(t *testing.T) {
+	msgs := recordFailures(t, func(t testing.TB) {
+		SlicesMatchUnorderedFunc(
+			t, []int{1, 1, 2}, []int{2, 1, 1},
+			func(l int, r int) bool { return l == r },
+		)
+	})
+	Eq(t, 0, len(msgs))
+
+	msgs = recordFailures(t, func(t testing.TB) {
+		SlicesMatchUnorderedFunc(
+			t, []int{1, 1, 2}, []int{2, 2, 1},
+			func(l int, r int) bool { return l == r },
+		)
+	})
+	Eq(t, 1, len(msgs))
+	True(t, strings.Contains(msgs[0], "Expected Index: 1 | Value: 1"))
+	True(t, strings.Contains(msgs[0], "Got Index: 1 | Value: 2"))
+}
+
+func TestSlicesMatchUnorderedFuncAugmentingPath(t *testing.T) {
+	// Greedily pairing 0 with 10 would leave 1 without a pair, so the match
+	// is only found by re-pairing 0 with 11.
+	eq := func(l int, r int) bool { return l == 0 || r == 10 }
+	msgs := recordFailures(t, func(t testing.TB) {
+		SlicesMatchUnorderedFunc(t, []int{0, 1}, []int{10, 11}, eq)
+	})
+	Eq(t, 0, len(msgs))
+}
+
+func TestSlicesMatchUnorderedFuncCallsEqForEveryPair(t *testing.T) {
+	calls := 0
+	SlicesMatchUnorderedFunc(
+		t, []int{1, 2, 3, 4}, []int{4, 3, 2, 1},
+		func(l int, r int) bool {
+			calls++
+			return l == r
+		},
+	)
+	Eq(t, 16, calls)
+}
+
+func TestSlicesMatchUnorderedFuncLength(t *testing.T) {
+	msgs := recordFailures(t, func(t testing.TB) {
+		SlicesMatchUnorderedFunc(
+			t, []int{1}, []int{1, 1},
+			func(l int, r int) bool { return l == r },
+		)
+	})
+	Eq(t, 1, len(msgs))
+	True(t, strings.Contains(msgs[0], "Slices do not match in length."))
+}
@@ -0,0 +1,109 @@
+package sbtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Reads the fixture file at the supplied path and returns its contents. A
+// relative path is resolved relative to the directory of the file that calls
+// LoadBytes rather than the working directory, so fixtures can be referenced
+// as "testdata/case1.txt" regardless of where the test is run from. The test
+// fails immediately if the file cannot be read.
+func LoadBytes(t testing.TB, path string) []byte {
+	t.Helper()
+	f, line := callerLoc()
+	rv, _ := readFile(t, fixturePath(path, f), f, line)
+	return rv
+}
+
+// Reads the JSON fixture file at the supplied path and decodes it into a value
+// of type T. Refer to [LoadBytes] for how the path is resolved. Decoding is
+// strict, fields in the file that do not exist in T fail the test, so typos in
+// fixtures are caught instead of silently producing zero values. The test
+// fails immediately if the file cannot be read or decoded.
+//
+//	c := sbtest.LoadJSON[Config](t, "testdata/case1.json")
+func LoadJSON[T any](t testing.TB, path string) T {
+	t.Helper()
+	f, line := callerLoc()
+	var rv T
+	path = fixturePath(path, f)
+	data, ok := readFile(t, path, f, line)
+	if !ok {
+		return rv
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&rv); err != nil {
+		FormatError(
+			t, nil, errChain(err),
+			fmt.Sprintf(
+				"The JSON fixture could not be decoded | Path: %s | Type: %T%s",
+				path, rv, jsonErrLine(data, err),
+			),
+			f, line,
+		)
+	}
+	return rv
+}
+
+// Reads the YAML fixture file at the supplied path and decodes it into a value
+// of type T. Refer to [LoadBytes] for how the path is resolved. Like
+// [LoadJSON] decoding is strict, fields in the file that do not exist in T fail
+// the test. The test fails immediately if the file cannot be read or decoded.
+func LoadYAML[T any](t testing.TB, path string) T {
+	t.Helper()
+	f, line := callerLoc()
+	var rv T
+	path = fixturePath(path, f)
+	data, ok := readFile(t, path, f, line)
+	if !ok {
+		return rv
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&rv); err != nil {
+		FormatError(
+			t, nil, errChain(err),
+			fmt.Sprintf(
+				"The YAML fixture could not be decoded | Path: %s | Type: %T",
+				path, rv,
+			),
+			f, line,
+		)
+	}
+	return rv
+}
+
+// Resolves a relative fixture path against the directory of the calling file.
+func fixturePath(path string, callerFile string) string {
+	if filepath.IsAbs(path) || !filepath.IsAbs(callerFile) {
+		return path
+	}
+	return filepath.Join(filepath.Dir(callerFile), path)
+}
+
+// Returns the line that the supplied JSON decoding error occurred on, if the
+// error reports an offset.
+func jsonErrLine(data []byte, err error) string {
+	var offset int64
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	default:
+		return ""
+	}
+	offset = min(offset, int64(len(data)))
+	return fmt.Sprintf(" | Line: %d", bytes.Count(data[:offset], []byte("\n"))+1)
+}
@@ -0,0 +1,124 @@
+package sbtest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// Tests that the supplied times represent instants that are within +/- delta
+// of each other. Monotonic clock readings and locations are ignored, so two
+// times that represent the same instant in different time zones are
+// considered equal. A delta of zero requires the instants to be identical.
+func EqTime(
+	t testing.TB,
+	expected time.Time,
+	got time.Time,
+	delta time.Duration,
+) {
+	t.Helper()
+	diff := expected.Round(0).Sub(got.Round(0))
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > delta {
+		f, line := callerLoc()
+		FormatError(
+			t,
+			expected.Format(time.RFC3339Nano), got.Format(time.RFC3339Nano),
+			fmt.Sprintf(
+				"The supplied times were not within %s of each other | Difference: %s",
+				delta, diff,
+			),
+			f, line,
+		)
+	}
+}
+
+// Tests that the supplied durations are within +/- tolerance of each other.
+func EqDuration(
+	t testing.TB,
+	expected time.Duration,
+	got time.Duration,
+	tolerance time.Duration,
+) {
+	t.Helper()
+	diff := expected - got
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > tolerance {
+		f, line := callerLoc()
+		FormatError(
+			t, expected, got,
+			fmt.Sprintf(
+				"The supplied durations were not within %s of each other | Difference: %s",
+				tolerance, diff,
+			),
+			f, line,
+		)
+	}
+}
+
+// Tests that the supplied duration is strictly less than the supplied bound.
+func DurationLessThan(t testing.TB, got time.Duration, bound time.Duration) {
+	t.Helper()
+	if got >= bound {
+		f, line := callerLoc()
+		FormatError(
+			t, fmt.Sprintf("< %s", bound), got,
+			"The supplied duration was not less than the expected bound.",
+			f, line,
+		)
+	}
+}
+
+// Tests that the time a is strictly before the time b. Both times are printed
+// in RFC3339Nano format on failure.
+func TimeBefore(t testing.TB, a time.Time, b time.Time) {
+	t.Helper()
+	if !a.Before(b) {
+		f, line := callerLoc()
+		FormatError(
+			t,
+			fmt.Sprintf(
+				"%s before %s",
+				a.Format(time.RFC3339Nano), b.Format(time.RFC3339Nano),
+			),
+			fmt.Sprintf(
+				"%s after or equal to %s",
+				a.Format(time.RFC3339Nano), b.Format(time.RFC3339Nano),
+			),
+			fmt.Sprintf(
+				"The first time was not before the second time | Difference: %s",
+				a.Sub(b),
+			),
+			f, line,
+		)
+	}
+}
+
+// Tests that the time a is strictly after the time b. Both times are printed in
+// RFC3339Nano format on failure.
+func TimeAfter(t testing.TB, a time.Time, b time.Time) {
+	t.Helper()
+	if !a.After(b) {
+		f, line := callerLoc()
+		FormatError(
+			t,
+			fmt.Sprintf(
+				"%s after %s",
+				a.Format(time.RFC3339Nano), b.Format(time.RFC3339Nano),
+			),
+			fmt.Sprintf(
+				"%s before or equal to %s",
+				a.Format(time.RFC3339Nano), b.Format(time.RFC3339Nano),
+			),
+			fmt.Sprintf(
+				"The first time was not after the second time | Difference: %s",
+				a.Sub(b),
+			),
+			f, line,
+		)
+	}
+}
@@ -0,0 +1,136 @@
+package sbtest
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type (
+	// A single file from a txtar archive.
+	txtarFile struct {
+		name string
+		data string
+	}
+)
+
+// Expands the txtar archive at the supplied path into a new temporary
+// directory and returns the path to the directory. This allows a fixture made
+// of several files to be kept in a single reviewable file under testdata. The
+// directory is removed when the test and all its subtests complete.
+//
+// A txtar archive is a comment followed by files, where each file starts with
+// a marker line of the form "-- name --" and contains every line up to the
+// next marker. The comment is ignored. File names are slash separated paths
+// relative to the root of the archive and intermediate directories are
+// created as needed.
+//
+//	Fixture for the config loader.
+//	-- config.yaml --
+//	include: extra.yaml
+//	-- extra.yaml --
+//	debug: true
+func LoadTxtar(t testing.TB, path string) string {
+	t.Helper()
+	f, line := callerLoc()
+	dir := t.TempDir()
+	files, ok := loadTxtar(t, path, f, line)
+	if !ok {
+		return dir
+	}
+	for _, iterFile := range files {
+		if !writeFile(
+			t, filepath.Join(dir, filepath.FromSlash(iterFile.name)),
+			iterFile.data, f, line,
+		) {
+			return dir
+		}
+	}
+	return dir
+}
+
+// Loads the txtar archive at the supplied path into a new [MemFS] instead of
+// a temporary directory. Refer to [LoadTxtar] for the archive format.
+func LoadTxtarFS(t testing.TB, path string) *MemFS {
+	t.Helper()
+	f, line := callerLoc()
+	files, _ := loadTxtar(t, path, f, line)
+	contents := make(map[string]string, len(files))
+	for _, iterFile := range files {
+		contents[iterFile.name] = iterFile.data
+	}
+	return NewMemFS(contents)
+}
+
+func loadTxtar(
+	t testing.TB,
+	path string,
+	file string,
+	line int,
+) ([]txtarFile, bool) {
+	t.Helper()
+	data, ok := readFile(t, path, file, line)
+	if !ok {
+		return nil, false
+	}
+	files := parseTxtar(data)
+	for _, iterFile := range files {
+		if !fs.ValidPath(iterFile.name) || iterFile.name == "." {
+			FormatError(
+				t, "valid slash separated path", iterFile.name,
+				fmt.Sprintf("The txtar archive contained an invalid file name | Path: %s", path),
+				file, line,
+			)
+			return nil, false
+		}
+	}
+	return files, true
+}
+
+// Parses the supplied txtar archive, discarding its comment. As in the txtar
+// format a missing final newline is added to the data of every file.
+func parseTxtar(data []byte) []txtarFile {
+	rv := []txtarFile{}
+	var cur *txtarFile
+	var sb strings.Builder
+	flush := func() {
+		if cur != nil {
+			cur.data = sb.String()
+			if cur.data != "" && !strings.HasSuffix(cur.data, "\n") {
+				cur.data += "\n"
+			}
+			rv = append(rv, *cur)
+		}
+		sb.Reset()
+	}
+	for len(data) > 0 {
+		lineEnd := bytes.IndexByte(data, '\n') + 1
+		if lineEnd == 0 {
+			lineEnd = len(data)
+		}
+		iterLine := string(data[:lineEnd])
+		data = data[lineEnd:]
+		if name, ok := txtarMarker(iterLine); ok {
+			flush()
+			cur = &txtarFile{name: name}
+			continue
+		}
+		sb.WriteString(iterLine)
+	}
+	flush()
+	return rv
+}
+
+// Returns the file name from the supplied line if it is a txtar file marker.
+func txtarMarker(line string) (string, bool) {
+	line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+	if !strings.HasPrefix(line, "-- ") || !strings.HasSuffix(line, " --") ||
+		len(line) < len("-- x --") {
+		return "", false
+	}
+	name := strings.TrimSpace(line[3 : len(line)-3])
+	return name, name != ""
+}
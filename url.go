@@ -0,0 +1,143 @@
+package sbtest
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Tests that the supplied URLs are semantically equal. Both URLs are parsed
+// and their scheme, user info, host, path, query, and fragment are compared
+// individually. The scheme and host are compared case-insensitively and the
+// query parameters are compared as multisets, so the order of the parameters
+// and the order of repeated values do not matter. Every component that differs
+// is listed on failure.
+func EqURL(t testing.TB, expected string, got string) {
+	t.Helper()
+	f, line := callerLoc()
+	eURL, err := url.Parse(expected)
+	if err != nil {
+		FormatError(
+			t, nil, errChain(err),
+			"The expected URL could not be parsed.",
+			f, line,
+		)
+		return
+	}
+	gURL, err := url.Parse(got)
+	if err != nil {
+		FormatError(
+			t, nil, errChain(err),
+			"The supplied URL could not be parsed.",
+			f, line,
+		)
+		return
+	}
+
+	var sb strings.Builder
+	urlComponentDiff(&sb, "Scheme", eURL.Scheme, gURL.Scheme, true)
+	urlComponentDiff(&sb, "User", eURL.User.String(), gURL.User.String(), false)
+	urlComponentDiff(&sb, "Host", eURL.Host, gURL.Host, true)
+	urlComponentDiff(&sb, "Path", eURL.EscapedPath(), gURL.EscapedPath(), false)
+	urlComponentDiff(&sb, "Fragment", eURL.Fragment, gURL.Fragment, false)
+	eQuery, err := url.ParseQuery(eURL.RawQuery)
+	if err != nil {
+		FormatError(
+			t, nil, errChain(err),
+			"The query of the expected URL could not be parsed.",
+			f, line,
+		)
+		return
+	}
+	gQuery, err := url.ParseQuery(gURL.RawQuery)
+	if err != nil {
+		FormatError(
+			t, nil, errChain(err),
+			"The query of the supplied URL could not be parsed.",
+			f, line,
+		)
+		return
+	}
+	for _, iterDiff := range valuesDiff(eQuery, gQuery) {
+		sb.WriteString("\n  Query " + iterDiff)
+	}
+	if sb.Len() > 0 {
+		FormatError(
+			t, expected, got,
+			"The supplied URLs were not equal but were expected to be.\nDifferences:"+
+				sb.String(),
+			f, line,
+		)
+	}
+}
+
+// Tests that the supplied values are equal. The values of each key are
+// compared as multisets, so the order of repeated values does not matter. This
+// is useful for testing code that builds query strings or form bodies. Every
+// key whose values differ is listed on failure.
+func EqValues(t testing.TB, expected url.Values, got url.Values) {
+	t.Helper()
+	diffs := valuesDiff(expected, got)
+	if len(diffs) == 0 {
+		return
+	}
+	f, line := callerLoc()
+	FormatError(
+		t, expected.Encode(), got.Encode(),
+		"The supplied values were not equal but were expected to be.\nDifferences:\n  "+
+			strings.Join(diffs, "\n  "),
+		f, line,
+	)
+}
+
+// Appends a line to sb if the supplied URL components differ.
+func urlComponentDiff(
+	sb *strings.Builder,
+	name string,
+	expected string,
+	got string,
+	foldCase bool,
+) {
+	if expected == got || (foldCase && strings.EqualFold(expected, got)) {
+		return
+	}
+	fmt.Fprintf(sb, "\n  %s | Expected: '%s' | Got: '%s'", name, expected, got)
+}
+
+// Returns one line for every key whose values differ between expected and got,
+// in sorted key order. The values of each key are compared as multisets.
+func valuesDiff(expected url.Values, got url.Values) []string {
+	keys := make([]string, 0, len(expected)+len(got))
+	for k := range expected {
+		keys = append(keys, k)
+	}
+	for k := range got {
+		if _, ok := expected[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	rv := []string{}
+	for _, k := range keys {
+		eVals, eOk := expected[k]
+		gVals, gOk := got[k]
+		switch {
+		case !gOk:
+			rv = append(rv, fmt.Sprintf("Key: %s | Missing | Expected: %q", k, eVals))
+		case !eOk:
+			rv = append(rv, fmt.Sprintf("Key: %s | Unexpected | Got: %q", k, gVals))
+		default:
+			eSorted, gSorted := slices.Sorted(slices.Values(eVals)),
+				slices.Sorted(slices.Values(gVals))
+			if !slices.Equal(eSorted, gSorted) {
+				rv = append(rv, fmt.Sprintf(
+					"Key: %s | Expected: %q | Got: %q", k, eVals, gVals,
+				))
+			}
+		}
+	}
+	return rv
+}
@@ -0,0 +1,281 @@
+package sbtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// Setting this environment variable to any non-empty value causes all
+// cassettes to be re-recorded, even if they already exist.
+const CassetteRecordEnvVar = "SBTEST_RECORD_CASSETTES"
+
+type (
+	// An [http.RoundTripper] that records real HTTP interactions to a cassette
+	// file and replays them on later runs. When the cassette file does not
+	// exist, or the environment variable named by [CassetteRecordEnvVar] is
+	// set, requests are sent with the underlying transport and the
+	// interactions are written to the cassette when the test completes, unless
+	// the test failed.
+	// Otherwise requests are answered from the cassette and never reach the
+	// network, allowing API clients to be tested hermetically.
+	//
+	// During replay each recorded interaction is used at most once, and
+	// requests are matched to interactions by their method, URL, and body.
+	// Requests that do not match any remaining interaction are answered with
+	// an error. All methods are safe for concurrent use.
+	VCR struct {
+		mu           sync.Mutex
+		path         string
+		recording    bool
+		transport    http.RoundTripper
+		redactors    []func(i *Interaction)
+		interactions []Interaction
+		used         []bool
+	}
+
+	// The contents of a cassette file.
+	Cassette struct {
+		Interactions []Interaction `json:"interactions"`
+	}
+
+	// A single request and the response that was received for it.
+	Interaction struct {
+		Request  CassetteRequest  `json:"request"`
+		Response CassetteResponse `json:"response"`
+	}
+
+	// A request that is stored in a cassette.
+	CassetteRequest struct {
+		Method string      `json:"method"`
+		URL    string      `json:"url"`
+		Header http.Header `json:"header,omitempty"`
+		Body   string      `json:"body,omitempty"`
+	}
+
+	// A response that is stored in a cassette.
+	CassetteResponse struct {
+		StatusCode int         `json:"status_code"`
+		Header     http.Header `json:"header,omitempty"`
+		Body       string      `json:"body,omitempty"`
+	}
+)
+
+// Creates a new VCR that uses the cassette file at the supplied path.
+// Cassettes are conventionally placed under the testdata directory of a
+// package. When recording, requests are sent with the supplied transport, or
+// with [http.DefaultTransport] if it is nil. The test fails if an existing
+// cassette cannot be loaded or if a recorded cassette cannot be written.
+func NewVCR(t testing.TB, path string, transport http.RoundTripper) *VCR {
+	t.Helper()
+	f, line := callerLoc()
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	rv := &VCR{path: path, transport: transport}
+
+	_, err := os.Stat(path)
+	rv.recording = os.Getenv(CassetteRecordEnvVar) != "" || os.IsNotExist(err)
+	if rv.recording {
+		t.Cleanup(func() {
+			t.Helper()
+			rv.save(t, f, line)
+		})
+		return rv
+	}
+
+	data, ok := readFile(t, path, f, line)
+	if !ok {
+		return rv
+	}
+	cassette := Cassette{}
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		FormatError(
+			t, nil, errChain(err),
+			fmt.Sprintf("The cassette could not be parsed | Path: %s", path),
+			f, line,
+		)
+		return rv
+	}
+	rv.interactions = cassette.Interactions
+	rv.used = make([]bool, len(cassette.Interactions))
+	return rv
+}
+
+// Returns an [http.Client] that sends all of its requests through the VCR.
+func (v *VCR) Client() *http.Client {
+	return &http.Client{Transport: v}
+}
+
+// Returns true if the VCR is recording new interactions rather than replaying
+// existing ones.
+func (v *VCR) Recording() bool {
+	return v.recording
+}
+
+// Registers a hook that is called with every interaction before it is written
+// to the cassette, allowing secrets such as tokens to be removed. During
+// replay the hooks are also called with an interaction built from each
+// incoming request, with an empty response, before it is matched so that
+// redacted values in the URL or body still match. Hooks are called in the
+// order they were registered.
+func (v *VCR) Redact(fn func(i *Interaction)) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.redactors = append(v.redactors, fn)
+}
+
+// Registers a redaction hook that replaces the values of the supplied request
+// and response headers with "REDACTED".
+func (v *VCR) RedactHeaders(names ...string) {
+	v.Redact(func(i *Interaction) {
+		for _, iterName := range names {
+			if i.Request.Header.Get(iterName) != "" {
+				i.Request.Header.Set(iterName, "REDACTED")
+			}
+			if i.Response.Header.Get(iterName) != "" {
+				i.Response.Header.Set(iterName, "REDACTED")
+			}
+		}
+	})
+}
+
+// Implements the [http.RoundTripper] interface. When recording, the underlying
+// transport is sent a copy of the request, the supplied request is not
+// modified.
+func (v *VCR) RoundTrip(req *http.Request) (*http.Response, error) {
+	sendReq, body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	interaction := Interaction{
+		Request: CassetteRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: req.Header.Clone(),
+			Body:   string(body),
+		},
+	}
+
+	if v.recording {
+		resp, err := v.record(sendReq, interaction)
+		if resp != nil && resp.Request == sendReq {
+			resp.Request = req
+		}
+		return resp, err
+	}
+	return v.replay(req, interaction)
+}
+
+func (v *VCR) record(
+	req *http.Request,
+	interaction Interaction,
+) (*http.Response, error) {
+	resp, err := v.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	interaction.Response = CassetteResponse{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		Body:       string(respBody),
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.redact(&interaction)
+	v.interactions = append(v.interactions, interaction)
+	return resp, nil
+}
+
+func (v *VCR) replay(
+	req *http.Request,
+	interaction Interaction,
+) (*http.Response, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.redact(&interaction)
+	for i, iterInteraction := range v.interactions {
+		if v.used[i] ||
+			iterInteraction.Request.Method != interaction.Request.Method ||
+			iterInteraction.Request.URL != interaction.Request.URL ||
+			iterInteraction.Request.Body != interaction.Request.Body {
+			continue
+		}
+		v.used[i] = true
+		resp := NewResponse(
+			req,
+			iterInteraction.Response.StatusCode,
+			iterInteraction.Response.Body,
+		)
+		if iterInteraction.Response.Header != nil {
+			resp.Header = iterInteraction.Response.Header.Clone()
+		}
+		return resp, nil
+	}
+	return nil, fmt.Errorf(
+		"sbtest: no unused interaction in cassette %s for request: %s %s",
+		v.path, interaction.Request.Method, interaction.Request.URL,
+	)
+}
+
+func (v *VCR) redact(i *Interaction) {
+	if i.Request.Header == nil {
+		i.Request.Header = http.Header{}
+	}
+	if i.Response.Header == nil {
+		i.Response.Header = http.Header{}
+	}
+	for _, iterFn := range v.redactors {
+		iterFn(i)
+	}
+	if len(i.Request.Header) == 0 {
+		i.Request.Header = nil
+	}
+	if len(i.Response.Header) == 0 {
+		i.Response.Header = nil
+	}
+}
+
+// Writes the recorded interactions to the cassette. Nothing is written if the
+// test failed, so a broken recording is not replayed by later runs.
+func (v *VCR) save(t testing.TB, file string, line int) {
+	t.Helper()
+	if t.Failed() {
+		t.Logf(
+			"sbtest: the cassette was not written because the test failed | Path: %s",
+			v.path,
+		)
+		return
+	}
+	v.mu.Lock()
+	cassette := Cassette{Interactions: append([]Interaction{}, v.interactions...)}
+	v.mu.Unlock()
+
+	data, err := json.MarshalIndent(cassette, "", "\t")
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(v.path), 0755)
+	}
+	if err == nil {
+		err = os.WriteFile(v.path, append(data, '\n'), 0644)
+	}
+	if err != nil {
+		FormatError(
+			t, nil, errChain(err),
+			fmt.Sprintf("The cassette could not be written | Path: %s", v.path),
+			file, line,
+		)
+	}
+}
@@ -0,0 +1,80 @@
+package sbtest
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVCRRecordAndReplay(t *testing.T) {
+	t.Setenv(CassetteRecordEnvVar, "")
+	path := filepath.Join(t.TempDir(), "testdata", "api.json")
+	upstream := NewMockTransport()
+	upstream.Stub(http.MethodPost, `/login$`, http.StatusOK, "token")
+
+	t.Run("record", func(t *testing.T) {
+		v := NewVCR(t, path, upstream)
+		True(t, v.Recording())
+		v.RedactHeaders("Authorization")
+		req, err := http.NewRequest(
+			http.MethodPost, "http://example.com/login", strings.NewReader("u=a"),
+		)
+		Nil(t, err)
+		req.Header.Set("Authorization", "secret")
+		origBody := req.Body
+		resp, err := v.RoundTrip(req)
+		Nil(t, err)
+		True(t, resp.Request == req)
+		True(t, req.Body == origBody)
+	})
+	data, err := os.ReadFile(path)
+	Nil(t, err)
+	True(t, strings.Contains(string(data), `"body": "u=a"`))
+	False(t, strings.Contains(string(data), "secret"))
+
+	t.Run("replay", func(t *testing.T) {
+		v := NewVCR(t, path, NewMockTransport())
+		False(t, v.Recording())
+		client := v.Client()
+		resp, err := client.Post(
+			"http://example.com/login", "text/plain", strings.NewReader("u=a"),
+		)
+		Nil(t, err)
+		body, err := io.ReadAll(resp.Body)
+		Nil(t, err)
+		Eq(t, "token", string(body))
+
+		// Each interaction is only replayed once.
+		_, err = client.Post(
+			"http://example.com/login", "text/plain", strings.NewReader("u=a"),
+		)
+		True(t, err != nil && strings.Contains(
+			err.Error(), "no unused interaction in cassette",
+		))
+	})
+	upstream.AssertRequestedTimes(t, http.MethodPost, `/login$`, 1)
+}
+
+func TestVCRFailedRecordingNotSaved(t *testing.T) {
+	t.Setenv(CassetteRecordEnvVar, "")
+	path := filepath.Join(t.TempDir(), "api.json")
+	upstream := NewMockTransport()
+	upstream.Stub(http.MethodGet, `/items$`, http.StatusOK, "[]")
+	// The cleanup of the VCR runs when the subtest completes and sees that the
+	// recorded run failed.
+	t.Run("record", func(t *testing.T) {
+		msgs := recordFailures(t, func(t testing.TB) {
+			v := NewVCR(t, path, upstream)
+			_, err := v.Client().Get("http://example.com/items")
+			Nil(t, err)
+			Eq(t, "[]", "[1]")
+		})
+		Eq(t, 1, len(msgs))
+	})
+	_, err := os.Stat(path)
+	True(t, os.IsNotExist(err))
+	upstream.AssertRequestedTimes(t, http.MethodGet, `/items$`, 1)
+}
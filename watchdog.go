@@ -0,0 +1,75 @@
+package sbtest
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Installs a watchdog that fails the test if it, including all its subtests
+// and cleanups registered after FailAfter was called, has not finished
+// within the supplied duration. This gives a deadlocked test actionable output
+// quickly instead of waiting for the global timeout of go test.
+//
+//	func TestServer(t *testing.T) {
+//		sbtest.FailAfter(t, 5*time.Second)
+//		...
+//	}
+//
+// A hung test cannot be stopped from another goroutine, so when the duration
+// expires the failure is written to stderr in the standard format of this
+// package, along with the stacks of all goroutines, and then the test binary
+// panics in the same way that it does when the timeout of go test expires.
+func FailAfter(t testing.TB, d time.Duration) {
+	t.Helper()
+	f, line := callerLoc()
+	done := make(chan struct{})
+	t.Cleanup(func() { close(done) })
+	go func() {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-done:
+			return
+		case <-timer.C:
+		}
+		failure := Failure{
+			Expected: fmt.Sprintf("finished within %s", d),
+			Got:      "still running",
+			Msg: fmt.Sprintf(
+				"The test did not finish before the deadline | Test: %s"+
+					"\nGoroutines:\n%s",
+				t.Name(), goroutineStacks(),
+			),
+			File:      f,
+			Line:      line,
+			Assertion: "FailAfter",
+		}
+		writeJSONFailure(t, failure)
+		fmt.Fprintf(
+			os.Stderr, "--- FAIL: %s\n%s\n",
+			t.Name(), activeFormatter().Format(failure),
+		)
+		panic(fmt.Sprintf("sbtest: test %s did not finish within %s", t.Name(), d))
+	}()
+}
+
+// Returns the stacks of all goroutines except the calling one, in the format
+// used by the runtime when a program panics.
+func goroutineStacks() string {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	// The runtime always lists the calling goroutine first.
+	_, rv, _ := strings.Cut(string(buf), "\n\n")
+	return strings.TrimRight(rv, "\n")
+}